@@ -0,0 +1,177 @@
+package fig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMaxFileSize limits the size of files read by DirectoryDriver, matching the 1MiB limit on
+// Kubernetes secrets
+const DefaultMaxFileSize = 1 << 20
+
+// DirectoryDriver supports reading from a directory of files, such as Docker secrets in
+// /run/secrets or a mounted Kubernetes Secret or ConfigMap volume. Each file name is a key and
+// the file's contents, with trailing newlines trimmed, are its value.
+//
+// Kubernetes volumes expose each key as a symlink into a hidden ..data directory, which is
+// followed transparently. Files are read on every Get, so updated volumes are picked up, and Watch
+// reports when they change.
+type DirectoryDriver struct {
+	dir     string
+	options directoryOptions
+}
+
+// DirectoryOption configures a DirectoryDriver
+type DirectoryOption func(*directoryOptions)
+
+type directoryOptions struct {
+	maxSize   int64
+	transform func(key string) string
+}
+
+// DirectoryMaxSize limits the size of files the driver will read. Larger files are an error.
+func DirectoryMaxSize(bytes int64) DirectoryOption {
+	return func(o *directoryOptions) {
+		o.maxSize = bytes
+	}
+}
+
+// DirectoryKeyTransform maps config keys to file names, e.g. DB_PASSWORD to db-password with
+// KeyToFlagName
+func DirectoryKeyTransform(transform func(key string) string) DirectoryOption {
+	return func(o *directoryOptions) {
+		o.transform = transform
+	}
+}
+
+// NewDirectoryDriver reads files from dir, which must exist
+func NewDirectoryDriver(dir string, opts ...DirectoryOption) (DirectoryDriver, error) {
+	options := directoryOptions{maxSize: DefaultMaxFileSize}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return DirectoryDriver{}, err
+	}
+	if !info.IsDir() {
+		return DirectoryDriver{}, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	return DirectoryDriver{dir: dir, options: options}, nil
+}
+
+// Get returns the trimmed contents of the file named for key. Missing files, directories and
+// hidden files return ErrConfigNotFound.
+func (d DirectoryDriver) Get(key string) (string, error) {
+	path, ok := d.path(key)
+	if !ok {
+		return "", ErrConfigNotFound
+	}
+
+	val, err := readValueFile(path, d.options.maxSize, true)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, errIsDirectory) {
+		return "", ErrConfigNotFound
+	}
+	return val, err
+}
+
+func (d DirectoryDriver) Name() string {
+	return "dir"
+}
+
+// Locate returns the file a key is read from
+func (d DirectoryDriver) Locate(key string) (string, int, bool) {
+	path, ok := d.path(key)
+	if !ok {
+		return "", 0, false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", 0, false
+	}
+	return path, 0, true
+}
+
+// Watch polls the directory, calling changed when files are added, removed or modified. Values
+// are always read fresh, so this only notifies of changes.
+func (d DirectoryDriver) Watch(ctx context.Context, changed func()) error {
+	last := d.stamps()
+	return poll(ctx, func() {
+		stamps := d.stamps()
+		unchanged := len(stamps) == len(last)
+		for name, stamp := range stamps {
+			unchanged = unchanged && stamp.equal(last[name])
+		}
+		if !unchanged {
+			last = stamps
+			changed()
+		}
+	})
+}
+
+// stamps identifies the current version of each visible file in the directory
+func (d DirectoryDriver) stamps() map[string]fileStamp {
+	stamps := map[string]fileStamp{}
+	entries, _ := os.ReadDir(d.dir)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		stamps[entry.Name()] = stampFile(filepath.Join(d.dir, entry.Name()))
+	}
+	return stamps
+}
+
+// path returns the file for key. Keys naming hidden files, such as Kubernetes' ..data, or
+// containing path separators are rejected.
+func (d DirectoryDriver) path(key string) (string, bool) {
+	name := key
+	if d.options.transform != nil {
+		name = d.options.transform(key)
+	}
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	return filepath.Join(d.dir, name), true
+}
+
+// returned by readValueFile for directories
+var errIsDirectory = errors.New("is a directory")
+
+// readValueFile reads a config value from a file, optionally trimming trailing newlines. Files
+// larger than maxSize bytes are an error.
+func readValueFile(path string, maxSize int64, trim bool) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s %w", path, errIsDirectory)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > maxSize {
+		return "", fmt.Errorf("%s exceeds maximum size of %d bytes", path, maxSize)
+	}
+
+	if trim {
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	return string(data), nil
+}
@@ -0,0 +1,135 @@
+package fig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirectoryDriver(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"DB_USER":     "admin\n",
+		"API_TOKEN":   "token\r\n",
+		"MULTILINE":   "line one\nline two\n",
+		".hidden":     "hidden",
+		"db-password": "hunter2",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "SUBDIR"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	driver, err := NewDirectoryDriver(dir)
+	if err != nil {
+		t.Fatalf("unexpected error creating directory driver: %s", err)
+	}
+
+	t.Run("file contents are trimmed", func(t *testing.T) {
+		for key, exp := range map[string]string{
+			"DB_USER":   "admin",
+			"API_TOKEN": "token",
+			"MULTILINE": "line one\nline two",
+		} {
+			val, err := driver.Get(key)
+			if err != nil || val != exp {
+				t.Errorf("expected %q for %s, got %q (%v)", exp, key, val, err)
+			}
+		}
+	})
+
+	t.Run("missing, hidden and unsafe keys return ErrConfigNotFound", func(t *testing.T) {
+		for _, key := range []string{"MISSING", ".hidden", "..data", "SUBDIR", "../DB_USER", "SUBDIR/x", ""} {
+			if _, err := driver.Get(key); !errors.Is(err, ErrConfigNotFound) {
+				t.Errorf("expected ErrConfigNotFound for %q, got %v", key, err)
+			}
+		}
+	})
+
+	t.Run("key transform maps keys to file names", func(t *testing.T) {
+		transformed, err := NewDirectoryDriver(dir, DirectoryKeyTransform(KeyToFlagName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		val, err := transformed.Get("DB_PASSWORD")
+		if err != nil || val != "hunter2" {
+			t.Errorf("expected hunter2, got %q (%v)", val, err)
+		}
+	})
+
+	t.Run("files over the size limit are an error", func(t *testing.T) {
+		limited, err := NewDirectoryDriver(dir, DirectoryMaxSize(4))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = limited.Get("DB_USER")
+		if err == nil || errors.Is(err, ErrConfigNotFound) {
+			t.Errorf("expected size limit error, got %v", err)
+		}
+	})
+
+	t.Run("Lookup reports the file", func(t *testing.T) {
+		_, src, err := New(driver).Lookup("DB_USER")
+		if err != nil || src.File != filepath.Join(dir, "DB_USER") {
+			t.Errorf("unexpected source %+v (%v)", src, err)
+		}
+	})
+
+	t.Run("missing directory fails construction", func(t *testing.T) {
+		if _, err := NewDirectoryDriver(filepath.Join(dir, "nope")); err == nil {
+			t.Errorf("expected error for missing directory")
+		}
+		if _, err := NewDirectoryDriver(filepath.Join(dir, "DB_USER")); err == nil {
+			t.Errorf("expected error for file instead of directory")
+		}
+	})
+}
+
+func TestDirectoryDriverKubernetesLayout(t *testing.T) {
+	dir := t.TempDir()
+
+	// mimic the atomic writer layout of a mounted Kubernetes volume
+	writeVersion := func(version, password string) {
+		versionDir := filepath.Join(dir, version)
+		if err := os.Mkdir(versionDir, 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(versionDir, "db-password"), []byte(password+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		tmpLink := filepath.Join(dir, "..data_tmp")
+		if err := os.Symlink(version, tmpLink); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmpLink, filepath.Join(dir, "..data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeVersion("..2024_01_01", "first")
+	if err := os.Symlink(filepath.Join("..data", "db-password"), filepath.Join(dir, "db-password")); err != nil {
+		t.Fatal(err)
+	}
+
+	driver, err := NewDirectoryDriver(dir, DirectoryKeyTransform(func(key string) string {
+		return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if val, err := driver.Get("DB_PASSWORD"); err != nil || val != "first" {
+		t.Errorf("expected first, got %q (%v)", val, err)
+	}
+
+	writeVersion("..2024_01_02", "second")
+	if val, err := driver.Get("DB_PASSWORD"); err != nil || val != "second" {
+		t.Errorf("expected updated volume to be read, got %q (%v)", val, err)
+	}
+}
@@ -1,8 +1,11 @@
 package fig
 
 import (
+	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -14,56 +17,298 @@ type Driver interface {
 	Name() string
 }
 
-// EnvironmentDriver supports reading from the environment and .env files
+// Locator may be implemented by drivers that read files, to report where a key was defined.
+// Config.Lookup and Unmarshal's WithSources include the location in the value's Source.
+type Locator interface {
+	// Should return ok false if the key's location is unknown or it wasn't read from a file
+	Locate(key string) (file string, line int, ok bool)
+}
+
+// MapDriver supports reading from an in-memory map, which is useful in tests and for
+// hard-coded fallbacks. Changes to the map are visible to Configs using the driver.
+type MapDriver map[string]string
+
+func (d MapDriver) Get(key string) (string, error) {
+	val, ok := d[key]
+	if !ok {
+		return "", ErrConfigNotFound
+	}
+	return val, nil
+}
+
+func (d MapDriver) Name() string {
+	return "map"
+}
+
+// EnvironmentDriver supports reading from the environment and .env files. Values read from files
+// are private to the driver unless InjectIntoProcess is used.
 type EnvironmentDriver struct {
-	env map[string]string
+	files   *watchedFiles[envFiles]
+	options envOptions
 }
 
+// EnvOption configures an EnvironmentDriver
+type EnvOption func(*envOptions)
+
+type envOptions struct {
+	files        []string
+	optional     bool
+	overload     bool
+	inject       bool
+	emptyIsUnset bool
+}
+
+// Files reads .env files in addition to the environment. Later files take precedence over
+// earlier ones.
+func Files(filenames ...string) EnvOption {
+	return func(o *envOptions) {
+		o.files = append(o.files, filenames...)
+	}
+}
+
+// OptionalFiles skips files that don't exist rather than failing. Skipped files are read if they
+// appear while the driver is watched.
+func OptionalFiles() EnvOption {
+	return func(o *envOptions) {
+		o.optional = true
+	}
+}
+
+// Overload gives values from files precedence over the real environment
+func Overload() EnvOption {
+	return func(o *envOptions) {
+		o.overload = true
+	}
+}
+
+// InjectIntoProcess sets values read from files in the process environment when the driver is
+// created, as godotenv.Load does. Variables already set are only replaced with Overload. Values
+// reloaded while the driver is watched are not injected.
+func InjectIntoProcess(inject bool) EnvOption {
+	return func(o *envOptions) {
+		o.inject = inject
+	}
+}
+
+// envFiles holds the values read from a set of .env files
+type envFiles struct {
+	present   []string
+	env       map[string]string
+	locations map[string]location
+}
+
+// location of a key in a file
+type location struct {
+	file string
+	line int
+}
+
+// EmptyIsUnset treats environment variables set to an empty string as unset, so that FOO= falls
+// through to the value from a file. By default an empty variable is a value like any other.
+func EmptyIsUnset() EnvOption {
+	return func(o *envOptions) {
+		o.emptyIsUnset = true
+	}
+}
+
+// NewEnvDriver reads from the environment and any .env files given with Files. By default the
+// real environment takes precedence over files, missing files are an error and file values are
+// kept private to the driver.
+func NewEnvDriver(opts ...EnvOption) (EnvironmentDriver, error) {
+	options := envOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	driver := EnvironmentDriver{options: options}
+	if len(options.files) == 0 {
+		return driver, nil
+	}
+
+	files, err := loadEnvFiles(options.files, options.optional)
+	if err != nil {
+		return EnvironmentDriver{}, err
+	}
+	driver.files = files
+
+	if options.inject {
+		if err := driver.inject(); err != nil {
+			return EnvironmentDriver{}, err
+		}
+	}
+	return driver, nil
+}
+
+// NewEnvironmentDriver reads from the environment and .env files, which must exist. It is
+// equivalent to NewEnvDriver(Files(filenames...)). A variable set to an empty string, such as
+// PORT=, is a value, so it replaces `default` tags and fails to parse into numeric fields. Use
+// NewEnvDriver with EmptyIsUnset to treat it as unset instead.
 func NewEnvironmentDriver(filenames ...string) (EnvironmentDriver, error) {
-	var err error
-	var env = map[string]string{}
-	if len(filenames) > 0 {
-		env, err = godotenv.Read(filenames...)
+	return NewEnvDriver(Files(filenames...))
+}
+
+// loadEnvFiles reads a set of .env files, with later files taking precedence. If optional,
+// missing files are skipped.
+func loadEnvFiles(filenames []string, optional bool) (*watchedFiles[envFiles], error) {
+	return loadWatchedFiles(filenames, func() (envFiles, error) {
+		present := filenames
+		if optional {
+			var err error
+			if present, err = presentFiles(filenames); err != nil {
+				return envFiles{}, err
+			}
+			if len(present) == 0 {
+				// godotenv reads .env when given no files
+				return envFiles{}, nil
+			}
+		}
+
+		env, err := godotenv.Read(present...)
 		if err != nil {
-			return EnvironmentDriver{}, err
+			return envFiles{}, err
+		}
+		locations, err := locateEnvKeys(present...)
+		if err != nil {
+			return envFiles{}, err
+		}
+		return envFiles{present: present, env: env, locations: locations}, nil
+	})
+}
+
+// inject sets the values read from files in the process environment
+func (d EnvironmentDriver) inject() error {
+	for key, val := range d.files.get().env {
+		if _, set := os.LookupEnv(key); set && !d.options.overload {
+			continue
+		}
+		if err := os.Setenv(key, val); err != nil {
+			return fmt.Errorf("failed to set %s in environment: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// presentFiles returns the files in filenames that exist
+func presentFiles(filenames []string) ([]string, error) {
+	present := []string{}
+	for _, f := range filenames {
+		if _, err := os.Stat(f); err == nil {
+			present = append(present, f)
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed checking for file %s: %w", f, err)
 		}
 	}
-	return EnvironmentDriver{env: env}, nil
+	return present, nil
 }
 
 // Get returns values from the environment, preferring real environment variables
-// above those from .env files
+// above those from .env files unless Overload is used. A variable set to an empty string is
+// returned as empty unless EmptyIsUnset is used.
 func (d EnvironmentDriver) Get(key string) (string, error) {
-	if envVal := os.Getenv(key); envVal != "" {
+	fileVal, inFile := d.files.get().env[key]
+	if inFile && d.options.overload {
+		return fileVal, nil
+	}
+
+	if envVal, ok := d.lookupEnv(key); ok {
 		return envVal, nil
 	}
 
-	val, ok := d.env[key]
-	if !ok {
+	if !inFile {
 		return "", ErrConfigNotFound
 	}
 
-	return val, nil
+	return fileVal, nil
 }
 
 func (d EnvironmentDriver) Name() string {
 	return "env"
 }
 
-// For use when environment files may not be present in all environments
-func NewOptionalFileEnvironmentDriver(filenames ...string) (EnvironmentDriver, error) {
-	presentFiles := []string{}
-	for _, f := range filenames {
-		if _, err := os.Stat(f); err == nil {
-			presentFiles = append(presentFiles, f)
-		} else if !os.IsNotExist(err) {
-			return EnvironmentDriver{}, fmt.Errorf("failed checking for file %s: %w", f, err)
-		}
+// Locate returns the .env file and line a key was read from. Keys set in the real environment
+// have no location.
+func (d EnvironmentDriver) Locate(key string) (string, int, bool) {
+	loc, ok := d.files.get().locations[key]
+	if ok && d.options.overload {
+		return loc.file, loc.line, true
 	}
-	if len(presentFiles) > 0 {
-		if err := godotenv.Load(presentFiles...); err != nil {
-			return EnvironmentDriver{}, err
+
+	if _, ok := d.lookupEnv(key); ok {
+		return "", 0, false
+	}
+
+	return loc.file, loc.line, ok
+}
+
+// lookupEnv reads key from the real environment
+func (d EnvironmentDriver) lookupEnv(key string) (string, bool) {
+	val, ok := os.LookupEnv(key)
+	if val == "" && d.options.emptyIsUnset {
+		return "", false
+	}
+	return val, ok
+}
+
+// Watch polls the .env files, reloading them when they change. Changes to the real environment
+// are not detected.
+func (d EnvironmentDriver) Watch(ctx context.Context, changed func()) error {
+	return d.files.watch(ctx, changed)
+}
+
+// only drivers reading .env files can be watched
+func (d EnvironmentDriver) watchable() bool {
+	return d.files != nil
+}
+
+// locateEnvKeys finds the line each key is defined on in a set of .env files. As with
+// godotenv.Read, definitions in later files replace those in earlier files.
+func locateEnvKeys(filenames ...string) (map[string]location, error) {
+	locations := map[string]location{}
+	for _, filename := range filenames {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(f)
+		for line := 1; scanner.Scan(); line++ {
+			if key, ok := envLineKey(scanner.Text()); ok {
+				locations[key] = location{file: filename, line: line}
+			}
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, err
 		}
 	}
-	return EnvironmentDriver{}, nil
+	return locations, nil
+}
+
+// envLineKey returns the key defined on a line of a .env file, if any
+func envLineKey(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", false
+	}
+	line = strings.TrimPrefix(line, "export ")
+
+	end := strings.IndexAny(line, "=:")
+	if end <= 0 {
+		return "", false
+	}
+	return strings.TrimSpace(line[:end]), true
+}
+
+// For use when environment files may not be present in all environments. As with godotenv.Load,
+// earlier files take precedence over later ones. It is equivalent to NewEnvDriver with
+// OptionalFiles() and the files in reverse order, so file values are no longer written to the
+// process environment. Use NewEnvDriver with InjectIntoProcess(true) if code relies on reading
+// file values with os.Getenv.
+func NewOptionalFileEnvironmentDriver(filenames ...string) (EnvironmentDriver, error) {
+	reversed := make([]string, len(filenames))
+	for i, filename := range filenames {
+		reversed[len(filenames)-1-i] = filename
+	}
+	return NewEnvDriver(Files(reversed...), OptionalFiles())
 }
@@ -0,0 +1,214 @@
+package fig
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Sentinel error for undefined config variable
+var ErrConfigNotFound = errors.New("config variable not found")
+
+// ErrUnsupportedType is the cause of a ParseError for a type fig cannot parse. fig supports
+// strings, bools, numeric types, slices and maps of those, the standard library types listed on
+// Unmarshal, and types with a registered parser or implementing Decoder or
+// encoding.TextUnmarshaler.
+var ErrUnsupportedType = errors.New("fig does not support config fields of this type")
+
+// ErrRequiredNotFound is the cause of a FieldError for a required field that no driver supplied
+var ErrRequiredNotFound = fmt.Errorf("required %w", ErrConfigNotFound)
+
+// ErrNotWatchable is returned by Config.Watch when none of the Config's drivers can be watched
+var ErrNotWatchable = errors.New("no config drivers support watching")
+
+// ErrInterpolationCycle is the cause of an InterpolationError for a value that refers back to
+// itself, directly or through other keys
+var ErrInterpolationCycle = errors.New("reference cycle")
+
+// KeyNotFoundError is returned when no configured driver has a key. It matches ErrConfigNotFound
+// with errors.Is.
+type KeyNotFoundError struct {
+	Key string
+}
+
+func (e *KeyNotFoundError) Error() string {
+	return fmt.Sprintf("%s: config key %s not found", ErrConfigNotFound, e.Key)
+}
+
+// Unwrap returns ErrConfigNotFound
+func (e *KeyNotFoundError) Unwrap() error {
+	return ErrConfigNotFound
+}
+
+// DriverError is returned when a driver fails to read a key for any reason other than the key
+// not being found
+type DriverError struct {
+	Driver string
+	Key    string
+	Cause  error
+}
+
+func (e *DriverError) Error() string {
+	return fmt.Sprintf("error reading key %s from driver %s: %s", e.Key, e.Driver, e.Cause)
+}
+
+func (e *DriverError) Unwrap() error {
+	return e.Cause
+}
+
+// InterpolationError is returned when a reference in a config value cannot be expanded, such as a
+// ${VAR:?message} reference to an unset key or a reference cycle
+type InterpolationError struct {
+	// Key is the key whose value contains the reference
+	Key string
+	// Ref is the referenced key
+	Ref   string
+	Cause error
+}
+
+func (e *InterpolationError) Error() string {
+	return fmt.Sprintf("failed to expand ${%s} in config key %s: %s", e.Ref, e.Key, e.Cause)
+}
+
+func (e *InterpolationError) Unwrap() error {
+	return e.Cause
+}
+
+// ValidationError is the cause of a FieldError for a value that fails a rule in its field's
+// `validate` tag
+type ValidationError struct {
+	Key   string
+	Value string
+	// Rule is the failed rule as written in the tag, e.g. "max=65535"
+	Rule  string
+	Cause error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("Configuration variable %s (value '%s') failed validation %s: %s", e.Key, e.Value, e.Rule, e.Cause)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Cause
+}
+
+// ParseError is returned when a config value cannot be parsed into the requested type
+type ParseError struct {
+	Key        string
+	Value      string
+	TargetType string
+	Cause      error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("Configuration variable %s (value '%s') not of requested type %s: %s", e.Key, e.Value, e.TargetType, e.Cause)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Cause
+}
+
+// build error for malformed variable
+func errConfigWrongType(key, value, expType string, cause error) error {
+	return &ParseError{Key: key, Value: value, TargetType: expType, Cause: cause}
+}
+
+// maskParseError hides a secret field's value in a ParseError, replacing the message of its
+// cause, which may quote the value, while keeping the cause for errors.Is and errors.As
+func maskParseError(err error) error {
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		return err
+	}
+	cause := parseErr.Cause
+	if !errors.Is(cause, ErrUnsupportedType) {
+		cause = &maskedError{err: cause}
+	}
+	return &ParseError{Key: parseErr.Key, Value: secretMask, TargetType: parseErr.TargetType, Cause: cause}
+}
+
+// maskedError hides the message of an error about a secret value
+type maskedError struct {
+	err error
+}
+
+func (e *maskedError) Error() string {
+	return "invalid value"
+}
+
+func (e *maskedError) Unwrap() error {
+	return e.err
+}
+
+// RangeError is the cause of a ParseError for a numeric config value that does not fit in its
+// target type
+type RangeError struct {
+	Key   string
+	Value string
+	Type  string
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("value '%s' out of range for type %s", e.Value, e.Type)
+}
+
+// Unwrap returns strconv.ErrRange
+func (e *RangeError) Unwrap() error {
+	return strconv.ErrRange
+}
+
+// build error for numeric variable that overflows its type
+func errConfigOutOfRange(key, value, expType string) error {
+	return errConfigWrongType(key, value, expType, &RangeError{Key: key, Value: value, Type: expType})
+}
+
+// FieldError describes a problem populating a single struct field in Unmarshal
+type FieldError struct {
+	// Field is the path to the struct field, e.g. DB.Host
+	Field string
+	// Key is the config key, including any prefixes
+	Key string
+	// Driver is the Name of the driver that returned the value or error, "default" if the value
+	// came from the `default` tag, and empty if no driver had the key
+	Driver string
+	// Err is the underlying cause
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if e.Driver == "" {
+		return fmt.Sprintf("field %s (config key %s): %s", e.Field, e.Key, e.Err)
+	}
+	return fmt.Sprintf("field %s (config key %s from %s): %s", e.Field, e.Key, e.Driver, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// UnmarshalErrors collects every field Unmarshal failed to populate. errors.Is and errors.As
+// search each FieldError and its cause.
+type UnmarshalErrors []*FieldError
+
+func (e UnmarshalErrors) Error() string {
+	if len(e) == 1 {
+		return "failed to unmarshal config: " + e[0].Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed to unmarshal config: %d errors", len(e))
+	for _, fieldErr := range e {
+		b.WriteString("\n\t")
+		b.WriteString(fieldErr.Error())
+	}
+	return b.String()
+}
+
+func (e UnmarshalErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fieldErr := range e {
+		errs[i] = fieldErr
+	}
+	return errs
+}
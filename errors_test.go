@@ -0,0 +1,104 @@
+package fig
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestErrorTypes(t *testing.T) {
+	conf := New(failingDriver{}, testDriver{vals: map[string]string{
+		"PORT": "http",
+		"BIG":  "300",
+	}})
+
+	t.Run("missing keys return KeyNotFoundError", func(t *testing.T) {
+		_, err := conf.GetString("MISSING")
+		var notFound *KeyNotFoundError
+		if !errors.As(err, &notFound) || notFound.Key != "MISSING" {
+			t.Errorf("expected KeyNotFoundError for MISSING, got %v", err)
+		}
+		if !errors.Is(err, ErrConfigNotFound) {
+			t.Errorf("expected KeyNotFoundError to match ErrConfigNotFound")
+		}
+	})
+
+	t.Run("driver failures return DriverError", func(t *testing.T) {
+		_, err := conf.GetString("BROKEN")
+		var driverErr *DriverError
+		if !errors.As(err, &driverErr) {
+			t.Fatalf("expected DriverError, got %v", err)
+		}
+		if driverErr.Driver != "failing" || driverErr.Key != "BROKEN" || driverErr.Cause == nil {
+			t.Errorf("DriverError has unexpected fields %+v", *driverErr)
+		}
+		if errors.Is(err, ErrConfigNotFound) {
+			t.Errorf("driver failure should not match ErrConfigNotFound")
+		}
+	})
+
+	t.Run("malformed values return ParseError", func(t *testing.T) {
+		_, err := conf.GetInt("PORT")
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("expected ParseError, got %v", err)
+		}
+		if parseErr.Key != "PORT" || parseErr.Value != "http" || parseErr.TargetType != "int" {
+			t.Errorf("ParseError has unexpected fields %+v", *parseErr)
+		}
+		var numErr *strconv.NumError
+		if !errors.As(err, &numErr) {
+			t.Errorf("expected ParseError to wrap the strconv error")
+		}
+	})
+
+	t.Run("secret fields mask their values in ParseError", func(t *testing.T) {
+		var cfg struct {
+			Key   int   `fig:"API_KEY" secret:"true"`
+			Small uint8 `fig:"BIG" secret:"true"`
+		}
+		err := New(testDriver{vals: map[string]string{"API_KEY": "sk-live-abc123", "BIG": "300"}}).Unmarshal(&cfg)
+		if err == nil || strings.Contains(err.Error(), "sk-live-abc123") || strings.Contains(err.Error(), "300") {
+			t.Fatalf("expected error without the secret values, got %v", err)
+		}
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || parseErr.Value != secretMask {
+			t.Errorf("expected masked ParseError, got %v", err)
+		}
+		var numErr *strconv.NumError
+		var rangeErr *RangeError
+		if !errors.As(err, &numErr) || !errors.As(err, &rangeErr) {
+			t.Errorf("expected masked errors to wrap their causes, got %v", err)
+		}
+	})
+
+	t.Run("out of range values return ParseError wrapping RangeError", func(t *testing.T) {
+		_, err := conf.GetUint8("BIG")
+		var parseErr *ParseError
+		var rangeErr *RangeError
+		if !errors.As(err, &parseErr) || !errors.As(err, &rangeErr) {
+			t.Errorf("expected ParseError wrapping RangeError, got %v", err)
+		}
+	})
+
+	t.Run("unsupported types return ParseError wrapping ErrUnsupportedType", func(t *testing.T) {
+		_, err := Get[chan int](conf, "PORT")
+		if !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("expected ErrUnsupportedType, got %v", err)
+		}
+	})
+
+	t.Run("Unmarshal field errors wrap typed causes", func(t *testing.T) {
+		var ts struct {
+			Port   int    `fig:"PORT"`
+			Broken string `fig:"BROKEN"`
+		}
+		err := conf.Unmarshal(&ts)
+		var parseErr *ParseError
+		var driverErr *DriverError
+		if !errors.As(err, &parseErr) || !errors.As(err, &driverErr) {
+			t.Errorf("expected Unmarshal error to contain ParseError and DriverError, got %v", err)
+		}
+	})
+}
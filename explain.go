@@ -0,0 +1,140 @@
+package fig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// mask shown in place of secret values
+const secretMask = "******"
+
+// FieldReport describes how a single config field resolves
+type FieldReport struct {
+	// Field is the path to the struct field, e.g. DB.Host
+	Field string
+	// Key is the config key, including any prefixes
+	Key string
+	// Value is the resolved raw value, masked for secret fields and for values interpolated from
+	// secret fields
+	Value string
+	// Set reports whether a driver or default supplied a value
+	Set bool
+	// Source is where the value came from. Source.Driver is "default" for default values.
+	Source Source
+	// Default reports whether the value came from the `default` tag
+	Default bool
+	// Required reports whether the field is tagged `required:"true"`
+	Required bool
+	// Secret reports whether the field is tagged `secret:"true"`
+	Secret bool
+	// Err is the error returned by a driver while resolving the key, if any
+	Err error
+}
+
+// Explanation describes the effective configuration of a struct, as returned by Explain
+type Explanation []FieldReport
+
+// Explain resolves every tagged field of dest, which should be a pointer to a struct, without
+// modifying it. It reports the value each field would receive from Unmarshal and where that value
+// comes from. Values of fields tagged `secret:"true"` are masked, as are values whose references
+// lead to a secret field's key, so the Explanation is safe to log or serve from a debug endpoint.
+func (c Config) Explain(dest interface{}) (Explanation, error) {
+	under, err := structValue(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	// visit never reports a field as set, so dest is left untouched
+	secrets := map[string]bool{}
+	c.walkStruct(under, "", "", func(f configField) bool {
+		if isSecret(f.field) {
+			secrets[f.key] = true
+		}
+		return false
+	})
+
+	var explanation Explanation
+	c.walkStruct(under, "", "", func(f configField) bool {
+		explanation = append(explanation, c.explainField(f, secrets))
+		return false
+	})
+
+	return explanation, nil
+}
+
+// resolve a single field's value without setting it. secrets holds the keys of secret fields.
+func (c Config) explainField(f configField, secrets map[string]bool) FieldReport {
+	report := FieldReport{
+		Field:    f.path,
+		Key:      f.key,
+		Required: isRequired(f.field),
+		Secret:   isSecret(f.field),
+	}
+
+	val, src, err := c.lookup(f.key)
+	raw, _, _ := c.lookupRaw(f.key)
+	switch {
+	case err == nil:
+		report.Value, report.Source, report.Set = val, src, true
+	case errors.Is(err, ErrConfigNotFound):
+		defaultVal, ok, err := c.defaultValue(f)
+		switch {
+		case err != nil:
+			report.Err = err
+		case ok:
+			raw = f.field.Tag.Get(defaultTag)
+			report.Value, report.Set, report.Default = defaultVal, true, true
+			report.Source = Source{Driver: defaultDriverName}
+		}
+	default:
+		report.Err = err
+	}
+
+	if report.Value != "" && (report.Secret || c.referencesSecret(raw, secrets)) {
+		report.Value = secretMask
+	}
+	return report
+}
+
+// referencesSecret reports whether raw, when interpolated, would include the value of a secret key
+func (c Config) referencesSecret(raw string, secrets map[string]bool) bool {
+	if !c.interpolate {
+		return false
+	}
+	refs := map[string]bool{}
+	c.references(raw, refs)
+	for key := range refs {
+		if secrets[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// isSecret reports whether a field is tagged `secret:"true"`
+func isSecret(field reflect.StructField) bool {
+	secretVal, ok := field.Tag.Lookup(secretTag)
+	return ok && strings.ToLower(secretVal) == "true"
+}
+
+// String renders the explanation as a table with one row per field
+func (e Explanation) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "FIELD\tKEY\tVALUE\tSOURCE\tREQUIRED")
+	for _, report := range e {
+		value, source := report.Value, report.Source.String()
+		switch {
+		case report.Err != nil:
+			value, source = "error: "+report.Err.Error(), "-"
+		case !report.Set:
+			value, source = "(unset)", "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", report.Field, report.Key, value, source, report.Required)
+	}
+	w.Flush()
+	return b.String()
+}
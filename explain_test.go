@@ -0,0 +1,102 @@
+package fig
+
+import (
+	"strings"
+	"testing"
+)
+
+type explainTestStruct struct {
+	Host     string `fig:"HOST" required:"true"`
+	Password string `fig:"PASSWORD" secret:"true"`
+	Timeout  string `fig:"TIMEOUT" default:"5s"`
+	Missing  string `fig:"MISSING"`
+	Broken   string `fig:"BROKEN"`
+	Replica  *struct {
+		Host string `fig:"HOST"`
+	} `prefix:"REPLICA_"`
+}
+
+func TestExplain(t *testing.T) {
+	conf := New(failingDriver{}, MapDriver{
+		"HOST":         "db.internal",
+		"PASSWORD":     "hunter2",
+		"REPLICA_HOST": "replica.internal",
+	})
+
+	var ts explainTestStruct
+	explanation, err := conf.Explain(&ts)
+	if err != nil {
+		t.Fatalf("unexpected error from Explain: %s", err)
+	}
+
+	t.Run("every tagged field is reported", func(t *testing.T) {
+		exp := []FieldReport{
+			{Field: "Host", Key: "HOST", Value: "db.internal", Set: true, Source: Source{Driver: "map"}, Required: true},
+			{Field: "Password", Key: "PASSWORD", Value: secretMask, Set: true, Source: Source{Driver: "map"}, Secret: true},
+			{Field: "Timeout", Key: "TIMEOUT", Value: "5s", Set: true, Source: Source{Driver: "default"}, Default: true},
+			{Field: "Missing", Key: "MISSING"},
+			{Field: "Broken", Key: "BROKEN"},
+			{Field: "Replica.Host", Key: "REPLICA_HOST", Value: "replica.internal", Set: true, Source: Source{Driver: "map"}},
+		}
+		if len(explanation) != len(exp) {
+			t.Fatalf("expected %d field reports, got %d", len(exp), len(explanation))
+		}
+		for i, report := range explanation {
+			if report.Field == "Broken" {
+				if report.Err == nil {
+					t.Errorf("expected driver error for Broken field")
+				}
+				report.Err = nil
+			}
+			if report != exp[i] {
+				t.Errorf("field report %d is %+v, expected %+v", i, report, exp[i])
+			}
+		}
+	})
+
+	t.Run("Explain does not modify destination", func(t *testing.T) {
+		if ts.Host != "" || ts.Replica != nil {
+			t.Errorf("Explain modified its destination: %+v", ts)
+		}
+	})
+
+	t.Run("values built from secrets are masked", func(t *testing.T) {
+		conf := WithInterpolation(New(MapDriver{
+			"PASSWORD": "hunter2",
+			"DSN":      "postgres://admin:${PASSWORD}@db",
+			"PROXY":    "${DSN}",
+			"HOST":     "db.internal",
+		}))
+
+		var ts struct {
+			Password string `fig:"PASSWORD" secret:"true"`
+			DSN      string `fig:"DSN"`
+			Proxy    string `fig:"PROXY"`
+			Backup   string `fig:"BACKUP" default:"${PASSWORD}"`
+			Label    string `fig:"LABEL" default:"${HOST}"`
+		}
+		explanation, err := conf.Explain(&ts)
+		if err != nil {
+			t.Fatalf("unexpected error from Explain: %s", err)
+		}
+
+		exp := []string{secretMask, secretMask, secretMask, secretMask, "db.internal"}
+		for i, report := range explanation {
+			if report.Value != exp[i] {
+				t.Errorf("unexpected value %q for %s, expected %q", report.Value, report.Key, exp[i])
+			}
+		}
+	})
+
+	t.Run("table masks secrets", func(t *testing.T) {
+		table := explanation.String()
+		if strings.Contains(table, "hunter2") {
+			t.Errorf("secret value leaked into explanation:\n%s", table)
+		}
+		for _, expected := range []string{"FIELD", "db.internal", secretMask, "(unset)", "default"} {
+			if !strings.Contains(table, expected) {
+				t.Errorf("explanation table does not contain %q:\n%s", expected, table)
+			}
+		}
+	})
+}
@@ -3,27 +3,19 @@ package fig
 import (
 	"errors"
 	"fmt"
-	"strconv"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"time"
 )
 
 // Config caches and retrieves configurations from the environment
 type Config struct {
-	drivers []Driver
-}
-
-const (
-	typeInt     string = "int"
-	typeInt64   string = "int64"
-	typeBool    string = "bool"
-	typeFloat64 string = "float64"
-)
-
-// Sentinel error for undefined config variable
-var ErrConfigNotFound = errors.New("config variable not found")
-
-// build error for malformed variable
-func errConfigWrongType(key, value, expType string) error {
-	return fmt.Errorf("Configuration variable %s (value '%s') not of requested type %s", key, value, expType)
+	drivers     []Driver
+	parsers     map[reflect.Type]parseFunc
+	interpolate bool
 }
 
 // New initializes a config object
@@ -33,221 +25,456 @@ func New(drivers ...Driver) Config {
 	}
 }
 
-// get string or cache
-func (c Config) get(key string) (string, error) {
+// Source describes where a config value came from
+type Source struct {
+	// Driver is the Name of the driver that supplied the value
+	Driver string
+	// File and Line locate the value for drivers that implement Locator, when known
+	File string
+	Line int
+}
+
+func (s Source) String() string {
+	if s.File == "" {
+		return s.Driver
+	}
+	if s.Line == 0 {
+		return fmt.Sprintf("%s (%s)", s.Driver, s.File)
+	}
+	return fmt.Sprintf("%s (%s:%d)", s.Driver, s.File, s.Line)
+}
+
+// Lookup retrieves the configured string for key along with the Source that supplied it
+func (c Config) Lookup(key string) (string, Source, error) {
+	return c.lookup(key)
+}
+
+// Has reports whether any driver has key. Driver errors are treated as the key being absent; use
+// GetOptional to tell them apart.
+func (c Config) Has(key string) bool {
+	_, present, _ := c.GetOptional(key)
+	return present
+}
+
+// GetOptional retrieves the configured string for key, reporting whether it was present. A
+// missing key is not an error, but driver failures are.
+func (c Config) GetOptional(key string) (string, bool, error) {
+	val, _, err := c.lookup(key)
+	if errors.Is(err, ErrConfigNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return val, true, nil
+}
+
+// lookup reads key from the drivers in configured order, expanding references to other keys if
+// interpolation is enabled
+func (c Config) lookup(key string) (string, Source, error) {
+	val, src, err := c.lookupRaw(key)
+	if err != nil || !c.interpolate {
+		return val, src, err
+	}
+
+	val, err = c.expand(key, val, []string{key})
+	if err != nil {
+		return "", src, err
+	}
+	return val, src, nil
+}
+
+// lookupRaw reads key from the drivers in configured order
+func (c Config) lookupRaw(key string) (string, Source, error) {
 	for _, driver := range c.drivers {
 		if val, err := driver.Get(key); err == nil {
-			return val, nil
+			return val, sourceOf(driver, key), nil
 		} else if errors.Is(err, ErrConfigNotFound) {
 			continue
 		} else {
-			return "", fmt.Errorf("error ")
+			return "", Source{}, &DriverError{Driver: driver.Name(), Key: key, Cause: err}
 		}
 	}
-	return "", fmt.Errorf("%w: config key %s not found", ErrConfigNotFound, key)
+	return "", Source{}, &KeyNotFoundError{Key: key}
 }
 
-// GetString retrieves the configured string
-func (c Config) GetString(key string) (string, error) {
-	return c.get(key)
+// build the Source for a key read from driver
+func sourceOf(driver Driver, key string) Source {
+	src := Source{Driver: driver.Name()}
+	if locator, ok := driver.(Locator); ok {
+		if file, line, ok := locator.Locate(key); ok {
+			src.File, src.Line = file, line
+		}
+	}
+	return src
 }
 
-// GetInt retrieves the configured int
-func (c Config) GetInt(key string) (int, error) {
+// get string or cache
+func (c Config) get(key string) (string, error) {
+	val, _, err := c.lookup(key)
+	return val, err
+}
+
+// Get retrieves the configured value for key parsed as T. T may be any type supported by
+// Unmarshal, or any type with a parser added via RegisterParser.
+func Get[T any](c Config, key string) (T, error) {
 	val, err := c.get(key)
 	if err != nil {
-		return 0, err
+		var zero T
+		return zero, err
 	}
 
-	i, err := strconv.Atoi(val)
+	return parseAs[T](c, key, val)
+}
+
+// MustGet retrieves the configured value for key parsed as T or panics if missing or malformed
+func MustGet[T any](c Config, key string) T {
+	val, err := Get[T](c, key)
 	if err != nil {
-		return i, errConfigWrongType(key, val, typeInt)
+		panic(err)
 	}
 
-	return i, nil
+	return val
 }
 
-// GetInt64 retrieves the configured int64
-func (c Config) GetInt64(key string) (int64, error) {
+// GetOr retrieves the configured value for key parsed as T or the provided default.
+// GetOr panics if the key is configured but cannot be parsed as T.
+func GetOr[T any](c Config, key string, defaultVal T) T {
 	val, err := c.get(key)
 	if err != nil {
-		return 0, err
+		return defaultVal
 	}
 
-	i, err := strconv.ParseInt(val, 10, 64)
+	parsed, err := parseAs[T](c, key, val)
 	if err != nil {
-		return i, errConfigWrongType(key, val, typeInt64)
+		panic(err)
 	}
 
-	return i, nil
+	return parsed
 }
 
-// GetBool retrieves the configured bool
-func (c Config) GetBool(key string) (bool, error) {
-	val, err := c.get(key)
+// parse a raw config value as T
+func parseAs[T any](c Config, key, value string) (T, error) {
+	var zero T
+	parsed, err := c.parseValue(reflect.TypeOf(&zero).Elem(), key, value, defaultFieldOptions)
 	if err != nil {
-		return false, err
+		return zero, err
 	}
 
-	i, err := strconv.ParseBool(val)
-	if err != nil {
-		return i, errConfigWrongType(key, val, typeBool)
-	}
+	return parsed.Interface().(T), nil
+}
 
-	return i, nil
+// GetString retrieves the configured string
+func (c Config) GetString(key string) (string, error) {
+	return Get[string](c, key)
 }
 
-// GetFloat64 retrieves the configured float64
-func (c Config) GetFloat64(key string) (float64, error) {
-	val, err := c.get(key)
-	if err != nil {
-		return 0, err
-	}
+// GetInt retrieves the configured int
+func (c Config) GetInt(key string) (int, error) {
+	return Get[int](c, key)
+}
 
-	i, err := strconv.ParseFloat(val, 64)
-	if err != nil {
-		return i, errConfigWrongType(key, val, typeFloat64)
-	}
+// GetInt64 retrieves the configured int64
+func (c Config) GetInt64(key string) (int64, error) {
+	return Get[int64](c, key)
+}
 
-	return i, nil
+// GetBool retrieves the configured bool
+func (c Config) GetBool(key string) (bool, error) {
+	return Get[bool](c, key)
+}
+
+// GetFloat64 retrieves the configured float64
+func (c Config) GetFloat64(key string) (float64, error) {
+	return Get[float64](c, key)
 }
 
 // MustGetString retrieves the configured string or panics if undefined
 func (c Config) MustGetString(key string) string {
-	value, err := c.get(key)
-	if err != nil {
-		panic(err)
-	}
-
-	return value
+	return MustGet[string](c, key)
 }
 
 // MustGetInt retrieves the configured int or panics
 func (c Config) MustGetInt(key string) int {
-	val, err := c.get(key)
-	if err != nil {
-		panic(err)
-	}
-
-	i, err := strconv.Atoi(val)
-	if err != nil {
-		panic(errConfigWrongType(key, val, typeInt))
-	}
-
-	return i
+	return MustGet[int](c, key)
 }
 
 // MustGetInt64 retrieves the configured int64 or panics if missing or malformed
 func (c Config) MustGetInt64(key string) int64 {
-	val, err := c.get(key)
-	if err != nil {
-		panic(err)
-	}
-
-	i, err := strconv.ParseInt(val, 10, 64)
-	if err != nil {
-		panic(errConfigWrongType(key, val, typeInt64))
-	}
-
-	return i
+	return MustGet[int64](c, key)
 }
 
 // MustGetBool retrieves the configured bool or panics if missing or malformed
 func (c Config) MustGetBool(key string) bool {
-	val, err := c.get(key)
-	if err != nil {
-		panic(err)
-	}
-
-	i, err := strconv.ParseBool(val)
-	if err != nil {
-		panic(errConfigWrongType(key, val, typeBool))
-	}
-
-	return i
+	return MustGet[bool](c, key)
 }
 
 // MustGetFloat64 retrieves the configured float64 or panics if missing or malformed
 func (c Config) MustGetFloat64(key string) float64 {
-	val, err := c.get(key)
-	if err != nil {
-		panic(err)
-	}
+	return MustGet[float64](c, key)
+}
 
-	i, err := strconv.ParseFloat(val, 64)
-	if err != nil {
-		panic(errConfigWrongType(key, val, typeFloat64))
-	}
+// GetStringOr retrieves the configured string or the provided default
+func (c Config) GetStringOr(key string, defaultString string) string {
+	return GetOr(c, key, defaultString)
+}
 
-	return i
+// GetIntOr retrieves the configured int or the provided default
+func (c Config) GetIntOr(key string, defaultInt int) int {
+	return GetOr(c, key, defaultInt)
 }
 
-// GetStringOr retrieves the configured string or the provided default
-func (c Config) GetStringOr(key, defaultString string) string {
-	value, err := c.get(key)
-	if err != nil {
-		return defaultString
-	}
+// GetInt64Or retrieves the configured int64 or the provided default
+func (c Config) GetInt64Or(key string, defaultInt64 int64) int64 {
+	return GetOr(c, key, defaultInt64)
+}
 
-	return value
+// GetBoolOr retrieves the configured bool or the provided default
+func (c Config) GetBoolOr(key string, defaultBool bool) bool {
+	return GetOr(c, key, defaultBool)
 }
 
-// GetIntOr retrieves the configured int or the provide ddefault
-func (c Config) GetIntOr(key string, defaultInt int) int {
-	val, err := c.get(key)
-	if err != nil {
-		return defaultInt
-	}
+// GetFloat64Or retrieves the configured float64 or the provided default
+func (c Config) GetFloat64Or(key string, defaultFloat64 float64) float64 {
+	return GetOr(c, key, defaultFloat64)
+}
 
-	i, err := strconv.Atoi(val)
-	if err != nil {
-		panic(errConfigWrongType(key, val, typeInt))
-	}
+// GetInt8 retrieves the configured int8
+func (c Config) GetInt8(key string) (int8, error) {
+	return Get[int8](c, key)
+}
 
-	return i
+// MustGetInt8 retrieves the configured int8 or panics if missing or malformed
+func (c Config) MustGetInt8(key string) int8 {
+	return MustGet[int8](c, key)
 }
 
-// GetInt64Or retrieves the configured int64 or the provided default
-func (c Config) GetInt64Or(key string, defaultInt64 int64) int64 {
-	val, err := c.get(key)
-	if err != nil {
-		return defaultInt64
-	}
+// GetInt8Or retrieves the configured int8 or the provided default
+func (c Config) GetInt8Or(key string, defaultInt8 int8) int8 {
+	return GetOr(c, key, defaultInt8)
+}
 
-	i, err := strconv.ParseInt(val, 10, 64)
-	if err != nil {
-		panic(errConfigWrongType(key, val, typeInt64))
-	}
+// GetInt16 retrieves the configured int16
+func (c Config) GetInt16(key string) (int16, error) {
+	return Get[int16](c, key)
+}
 
-	return i
+// MustGetInt16 retrieves the configured int16 or panics if missing or malformed
+func (c Config) MustGetInt16(key string) int16 {
+	return MustGet[int16](c, key)
 }
 
-// GetBoolOr retrieves the configured bool or the provided default
-func (c Config) GetBoolOr(key string, defaultBool bool) bool {
-	val, err := c.get(key)
-	if err != nil {
-		return defaultBool
-	}
+// GetInt16Or retrieves the configured int16 or the provided default
+func (c Config) GetInt16Or(key string, defaultInt16 int16) int16 {
+	return GetOr(c, key, defaultInt16)
+}
 
-	i, err := strconv.ParseBool(val)
-	if err != nil {
-		panic(errConfigWrongType(key, val, typeBool))
-	}
+// GetInt32 retrieves the configured int32
+func (c Config) GetInt32(key string) (int32, error) {
+	return Get[int32](c, key)
+}
 
-	return i
+// MustGetInt32 retrieves the configured int32 or panics if missing or malformed
+func (c Config) MustGetInt32(key string) int32 {
+	return MustGet[int32](c, key)
 }
 
-// GetFloat64Or retrieves the configured float64 or the provided default
-func (c Config) GetFloat64Or(key string, defaultFloat64 float64) float64 {
-	val, err := c.get(key)
-	if err != nil {
-		return defaultFloat64
-	}
+// GetInt32Or retrieves the configured int32 or the provided default
+func (c Config) GetInt32Or(key string, defaultInt32 int32) int32 {
+	return GetOr(c, key, defaultInt32)
+}
 
-	i, err := strconv.ParseFloat(val, 64)
-	if err != nil {
-		panic(errConfigWrongType(key, val, typeFloat64))
-	}
+// GetUint retrieves the configured uint
+func (c Config) GetUint(key string) (uint, error) {
+	return Get[uint](c, key)
+}
+
+// MustGetUint retrieves the configured uint or panics if missing or malformed
+func (c Config) MustGetUint(key string) uint {
+	return MustGet[uint](c, key)
+}
+
+// GetUintOr retrieves the configured uint or the provided default
+func (c Config) GetUintOr(key string, defaultUint uint) uint {
+	return GetOr(c, key, defaultUint)
+}
+
+// GetUint8 retrieves the configured uint8
+func (c Config) GetUint8(key string) (uint8, error) {
+	return Get[uint8](c, key)
+}
+
+// MustGetUint8 retrieves the configured uint8 or panics if missing or malformed
+func (c Config) MustGetUint8(key string) uint8 {
+	return MustGet[uint8](c, key)
+}
+
+// GetUint8Or retrieves the configured uint8 or the provided default
+func (c Config) GetUint8Or(key string, defaultUint8 uint8) uint8 {
+	return GetOr(c, key, defaultUint8)
+}
+
+// GetUint16 retrieves the configured uint16
+func (c Config) GetUint16(key string) (uint16, error) {
+	return Get[uint16](c, key)
+}
+
+// MustGetUint16 retrieves the configured uint16 or panics if missing or malformed
+func (c Config) MustGetUint16(key string) uint16 {
+	return MustGet[uint16](c, key)
+}
+
+// GetUint16Or retrieves the configured uint16 or the provided default
+func (c Config) GetUint16Or(key string, defaultUint16 uint16) uint16 {
+	return GetOr(c, key, defaultUint16)
+}
+
+// GetUint32 retrieves the configured uint32
+func (c Config) GetUint32(key string) (uint32, error) {
+	return Get[uint32](c, key)
+}
+
+// MustGetUint32 retrieves the configured uint32 or panics if missing or malformed
+func (c Config) MustGetUint32(key string) uint32 {
+	return MustGet[uint32](c, key)
+}
+
+// GetUint32Or retrieves the configured uint32 or the provided default
+func (c Config) GetUint32Or(key string, defaultUint32 uint32) uint32 {
+	return GetOr(c, key, defaultUint32)
+}
+
+// GetUint64 retrieves the configured uint64
+func (c Config) GetUint64(key string) (uint64, error) {
+	return Get[uint64](c, key)
+}
+
+// MustGetUint64 retrieves the configured uint64 or panics if missing or malformed
+func (c Config) MustGetUint64(key string) uint64 {
+	return MustGet[uint64](c, key)
+}
+
+// GetUint64Or retrieves the configured uint64 or the provided default
+func (c Config) GetUint64Or(key string, defaultUint64 uint64) uint64 {
+	return GetOr(c, key, defaultUint64)
+}
+
+// GetFloat32 retrieves the configured float32
+func (c Config) GetFloat32(key string) (float32, error) {
+	return Get[float32](c, key)
+}
+
+// MustGetFloat32 retrieves the configured float32 or panics if missing or malformed
+func (c Config) MustGetFloat32(key string) float32 {
+	return MustGet[float32](c, key)
+}
+
+// GetFloat32Or retrieves the configured float32 or the provided default
+func (c Config) GetFloat32Or(key string, defaultFloat32 float32) float32 {
+	return GetOr(c, key, defaultFloat32)
+}
+
+// GetDuration retrieves the configured time.Duration
+func (c Config) GetDuration(key string) (time.Duration, error) {
+	return Get[time.Duration](c, key)
+}
+
+// MustGetDuration retrieves the configured time.Duration or panics if missing or malformed
+func (c Config) MustGetDuration(key string) time.Duration {
+	return MustGet[time.Duration](c, key)
+}
+
+// GetDurationOr retrieves the configured time.Duration or the provided default
+func (c Config) GetDurationOr(key string, defaultDuration time.Duration) time.Duration {
+	return GetOr(c, key, defaultDuration)
+}
+
+// GetTime retrieves the configured RFC 3339 time.Time
+func (c Config) GetTime(key string) (time.Time, error) {
+	return Get[time.Time](c, key)
+}
+
+// MustGetTime retrieves the configured RFC 3339 time.Time or panics if missing or malformed
+func (c Config) MustGetTime(key string) time.Time {
+	return MustGet[time.Time](c, key)
+}
+
+// GetTimeOr retrieves the configured RFC 3339 time.Time or the provided default
+func (c Config) GetTimeOr(key string, defaultTime time.Time) time.Time {
+	return GetOr(c, key, defaultTime)
+}
+
+// GetURL retrieves the configured *url.URL
+func (c Config) GetURL(key string) (*url.URL, error) {
+	return Get[*url.URL](c, key)
+}
+
+// MustGetURL retrieves the configured *url.URL or panics if missing or malformed
+func (c Config) MustGetURL(key string) *url.URL {
+	return MustGet[*url.URL](c, key)
+}
+
+// GetURLOr retrieves the configured *url.URL or the provided default
+func (c Config) GetURLOr(key string, defaultURL *url.URL) *url.URL {
+	return GetOr(c, key, defaultURL)
+}
+
+// GetIP retrieves the configured net.IP
+func (c Config) GetIP(key string) (net.IP, error) {
+	return Get[net.IP](c, key)
+}
+
+// MustGetIP retrieves the configured net.IP or panics if missing or malformed
+func (c Config) MustGetIP(key string) net.IP {
+	return MustGet[net.IP](c, key)
+}
+
+// GetIPOr retrieves the configured net.IP or the provided default
+func (c Config) GetIPOr(key string, defaultIP net.IP) net.IP {
+	return GetOr(c, key, defaultIP)
+}
+
+// GetIPNet retrieves the configured *net.IPNet (CIDR notation)
+func (c Config) GetIPNet(key string) (*net.IPNet, error) {
+	return Get[*net.IPNet](c, key)
+}
+
+// MustGetIPNet retrieves the configured *net.IPNet (CIDR notation) or panics if missing or malformed
+func (c Config) MustGetIPNet(key string) *net.IPNet {
+	return MustGet[*net.IPNet](c, key)
+}
+
+// GetIPNetOr retrieves the configured *net.IPNet (CIDR notation) or the provided default
+func (c Config) GetIPNetOr(key string, defaultIPNet *net.IPNet) *net.IPNet {
+	return GetOr(c, key, defaultIPNet)
+}
+
+// GetRegexp retrieves the configured *regexp.Regexp
+func (c Config) GetRegexp(key string) (*regexp.Regexp, error) {
+	return Get[*regexp.Regexp](c, key)
+}
+
+// MustGetRegexp retrieves the configured *regexp.Regexp or panics if missing or malformed
+func (c Config) MustGetRegexp(key string) *regexp.Regexp {
+	return MustGet[*regexp.Regexp](c, key)
+}
+
+// GetRegexpOr retrieves the configured *regexp.Regexp or the provided default
+func (c Config) GetRegexpOr(key string, defaultRegexp *regexp.Regexp) *regexp.Regexp {
+	return GetOr(c, key, defaultRegexp)
+}
+
+// GetFileMode retrieves the configured octal os.FileMode
+func (c Config) GetFileMode(key string) (os.FileMode, error) {
+	return Get[os.FileMode](c, key)
+}
+
+// MustGetFileMode retrieves the configured octal os.FileMode or panics if missing or malformed
+func (c Config) MustGetFileMode(key string) os.FileMode {
+	return MustGet[os.FileMode](c, key)
+}
 
-	return i
+// GetFileModeOr retrieves the configured octal os.FileMode or the provided default
+func (c Config) GetFileModeOr(key string, defaultFileMode os.FileMode) os.FileMode {
+	return GetOr(c, key, defaultFileMode)
 }
@@ -3,7 +3,10 @@ package fig
 import (
 	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 type testDriver struct {
@@ -417,4 +420,405 @@ func TestDrivers(t *testing.T) {
 			t.Error("env does not take precedence over env files")
 		}
 	})
+
+	t.Run("earlier optional files take precedence over later ones", func(t *testing.T) {
+		first := writeTestFile(t, "first.env", "FIG_ORDER=first\n")
+		second := writeTestFile(t, "second.env", "FIG_ORDER=second\nFIG_SECOND=second\n")
+
+		driver, err := NewOptionalFileEnvironmentDriver(first, second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val, _ := driver.Get("FIG_ORDER"); val != "first" {
+			t.Errorf("expected first file to take precedence, got %s", val)
+		}
+		if val, _ := driver.Get("FIG_SECOND"); val != "second" {
+			t.Errorf("expected keys only in the second file to be read, got %s", val)
+		}
+		if file, _, _ := driver.Locate("FIG_ORDER"); file != first {
+			t.Errorf("expected FIG_ORDER to be located in the first file, got %s", file)
+		}
+	})
+}
+
+func TestEnvDriverOptions(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.env")
+	local := filepath.Join(dir, "local.env")
+	if err := os.WriteFile(base, []byte("FIG_LEVEL=base\nFIG_BASE=base\nFIG_SHARED=file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(local, []byte("FIG_LEVEL=local\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.env")
+
+	t.Run("file values stay private by default", func(t *testing.T) {
+		t.Setenv("FIG_SHARED", "process")
+		driver, err := NewEnvDriver(Files(base, missing, local), OptionalFiles())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if val, _ := driver.Get("FIG_LEVEL"); val != "local" {
+			t.Errorf("expected later file to take precedence, got %s", val)
+		}
+		if val, _ := driver.Get("FIG_SHARED"); val != "process" {
+			t.Errorf("expected environment to take precedence, got %s", val)
+		}
+		if _, set := os.LookupEnv("FIG_BASE"); set {
+			t.Errorf("expected file values not to be written to the environment")
+		}
+	})
+
+	t.Run("missing files are an error unless optional", func(t *testing.T) {
+		if _, err := NewEnvDriver(Files(base, missing)); err == nil {
+			t.Errorf("expected error for missing file")
+		}
+	})
+
+	t.Run("overload", func(t *testing.T) {
+		t.Setenv("FIG_SHARED", "process")
+		driver, err := NewEnvDriver(Files(base), Overload())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if val, _ := driver.Get("FIG_SHARED"); val != "file" {
+			t.Errorf("expected file to take precedence, got %s", val)
+		}
+		if file, line, ok := driver.Locate("FIG_SHARED"); !ok || file != base || line != 3 {
+			t.Errorf("unexpected location %s:%d", file, line)
+		}
+	})
+
+	t.Run("empty variables are set", func(t *testing.T) {
+		t.Setenv("FIG_LEVEL", "")
+		driver, err := NewEnvDriver(Files(base))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val, err := driver.Get("FIG_LEVEL"); err != nil || val != "" {
+			t.Errorf("expected empty value from environment, got %q (%v)", val, err)
+		}
+		if _, _, ok := driver.Locate("FIG_LEVEL"); ok {
+			t.Errorf("expected no file location for environment value")
+		}
+
+		driver, err = NewEnvDriver(Files(base), EmptyIsUnset())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val, err := driver.Get("FIG_LEVEL"); err != nil || val != "base" {
+			t.Errorf("expected file value with EmptyIsUnset, got %q (%v)", val, err)
+		}
+	})
+
+	t.Run("empty variables override defaults", func(t *testing.T) {
+		t.Setenv("FIG_PORT", "")
+		var cfg struct {
+			Port int `fig:"FIG_PORT" default:"8080"`
+		}
+
+		driver, err := NewEnvDriver()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var parseErr *ParseError
+		if err := New(driver).Unmarshal(&cfg); !errors.As(err, &parseErr) {
+			t.Errorf("expected ParseError for empty FIG_PORT, got %v", err)
+		}
+
+		driver, err = NewEnvDriver(EmptyIsUnset())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := New(driver).Unmarshal(&cfg); err != nil || cfg.Port != 8080 {
+			t.Errorf("expected default with EmptyIsUnset, got %d (%v)", cfg.Port, err)
+		}
+	})
+
+	t.Run("inject into process", func(t *testing.T) {
+		t.Setenv("FIG_SHARED", "process")
+		t.Setenv("FIG_BASE", "")
+		os.Unsetenv("FIG_BASE")
+
+		if _, err := NewEnvDriver(Files(base), InjectIntoProcess(true)); err != nil {
+			t.Fatal(err)
+		}
+		if val := os.Getenv("FIG_BASE"); val != "base" {
+			t.Errorf("expected FIG_BASE to be injected, got %q", val)
+		}
+		if val := os.Getenv("FIG_SHARED"); val != "process" {
+			t.Errorf("expected FIG_SHARED to be kept without Overload, got %q", val)
+		}
+
+		if _, err := NewEnvDriver(Files(base), InjectIntoProcess(true), Overload()); err != nil {
+			t.Fatal(err)
+		}
+		if val := os.Getenv("FIG_SHARED"); val != "file" {
+			t.Errorf("expected FIG_SHARED to be replaced with Overload, got %q", val)
+		}
+	})
+}
+
+type testLevel int
+
+func TestGeneric(t *testing.T) {
+	RegisterParser(func(value string) (testLevel, error) {
+		switch value {
+		case "debug":
+			return 0, nil
+		case "info":
+			return 1, nil
+		}
+		return 0, errors.New("unknown level")
+	})
+
+	driver := testDriver{
+		vals: map[string]string{
+			"A":     "a",
+			"C":     "3",
+			"LEVEL": "info",
+			"BAD":   "loud",
+		},
+	}
+	config := New(driver)
+
+	t.Run("Get parses built-in types", func(t *testing.T) {
+		val, err := Get[int64](config, "C")
+		if err != nil {
+			t.Errorf("unexpected non-nil error for known config key: %s", err)
+		}
+		if val != 3 {
+			t.Errorf("unexpected value %d for config key C", val)
+		}
+	})
+
+	t.Run("Get parses pointer types", func(t *testing.T) {
+		val, err := Get[*string](config, "A")
+		if err != nil {
+			t.Errorf("unexpected non-nil error for known config key: %s", err)
+		}
+		if val == nil || *val != "a" {
+			t.Errorf("unexpected value %v for config key A", val)
+		}
+	})
+
+	t.Run("Get uses registered parsers", func(t *testing.T) {
+		val, err := Get[testLevel](config, "LEVEL")
+		if err != nil {
+			t.Errorf("unexpected non-nil error for known config key: %s", err)
+		}
+		if val != 1 {
+			t.Errorf("unexpected value %d for config key LEVEL", val)
+		}
+
+		if _, err := Get[testLevel](config, "BAD"); err == nil {
+			t.Errorf("expected error from registered parser")
+		}
+	})
+
+	t.Run("Get errors on unsupported types", func(t *testing.T) {
+		if _, err := Get[complex128](config, "C"); err == nil {
+			t.Errorf("expected error for unsupported type")
+		}
+	})
+
+	t.Run("GetOr returns default value", func(t *testing.T) {
+		val := GetOr(config, "Z", testLevel(7))
+		if val != 7 {
+			t.Errorf("got unexpected value %d from GetOr call on unknown key", val)
+		}
+	})
+
+	t.Run("MustGet panics on malformed value", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected panic for malformed value")
+			}
+		}()
+		MustGet[int](config, "A")
+	})
+}
+
+func TestStdlibGetters(t *testing.T) {
+	config := New(testDriver{
+		vals: map[string]string{
+			"TIMEOUT":  "5s",
+			"ENDPOINT": "https://example.com",
+			"BAD":      "five seconds",
+		},
+	})
+
+	t.Run("GetDuration returns value and no error", func(t *testing.T) {
+		val, err := config.GetDuration("TIMEOUT")
+		if err != nil {
+			t.Errorf("unexpected non-nil error for known config key: %s", err)
+		}
+		if val != 5*time.Second {
+			t.Errorf("unexpected value %s for config key TIMEOUT", val)
+		}
+	})
+
+	t.Run("GetDuration errors on malformed value", func(t *testing.T) {
+		if _, err := config.GetDuration("BAD"); err == nil {
+			t.Errorf("expected error for malformed duration")
+		}
+	})
+
+	t.Run("GetDurationOr returns default value", func(t *testing.T) {
+		val := config.GetDurationOr("Z", time.Minute)
+		if val != time.Minute {
+			t.Errorf("got unexpected value %s from GetDurationOr call on unknown key", val)
+		}
+	})
+
+	t.Run("MustGetURL does not panic", func(t *testing.T) {
+		val := config.MustGetURL("ENDPOINT")
+		if val.Host != "example.com" {
+			t.Errorf("got unexpected value %s from MustGetURL call on key ENDPOINT", val)
+		}
+	})
+}
+
+func TestNumericGetters(t *testing.T) {
+	config := New(testDriver{
+		vals: map[string]string{
+			"PORT":  "443",
+			"BIG":   "70000",
+			"RATIO": "0.5",
+		},
+	})
+
+	t.Run("GetUint16 returns value and no error", func(t *testing.T) {
+		val, err := config.GetUint16("PORT")
+		if err != nil {
+			t.Errorf("unexpected non-nil error for known config key: %s", err)
+		}
+		if val != 443 {
+			t.Errorf("unexpected value %d for config key PORT", val)
+		}
+	})
+
+	t.Run("GetUint16 returns RangeError on overflow", func(t *testing.T) {
+		_, err := config.GetUint16("BIG")
+		var rangeErr *RangeError
+		if !errors.As(err, &rangeErr) {
+			t.Errorf("expected RangeError for out of range value: got %v", err)
+		}
+	})
+
+	t.Run("GetFloat32Or returns value instead of default", func(t *testing.T) {
+		val := config.GetFloat32Or("RATIO", 1)
+		if val != 0.5 {
+			t.Errorf("got unexpected value %f from GetFloat32Or call on key RATIO", val)
+		}
+	})
+}
+
+func TestGetOptional(t *testing.T) {
+	conf := New(testDriver{vals: map[string]string{"EMPTY": "", "HOST": "db.local"}}, failingDriver{})
+
+	cases := []struct {
+		key      string
+		expected string
+		present  bool
+	}{
+		{"HOST", "db.local", true},
+		{"EMPTY", "", true},
+		{"MISSING", "", false},
+	}
+	for _, tc := range cases {
+		val, present, err := conf.GetOptional(tc.key)
+		if err != nil || val != tc.expected || present != tc.present {
+			t.Errorf("%s: expected (%q, %v), got (%q, %v, %v)", tc.key, tc.expected, tc.present, val, present, err)
+		}
+		if has := conf.Has(tc.key); has != tc.present {
+			t.Errorf("%s: expected Has %v, got %v", tc.key, tc.present, has)
+		}
+	}
+
+	var driverErr *DriverError
+	if _, present, err := conf.GetOptional("BROKEN"); present || !errors.As(err, &driverErr) {
+		t.Errorf("expected DriverError for BROKEN, got %v", err)
+	}
+	if conf.Has("BROKEN") {
+		t.Errorf("expected Has to be false for a failing driver")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), "local.env")
+	contents := "# comment\nFIG_LOOKUP_HOST=db.local\n\nexport FIG_LOOKUP_PORT=5432\n"
+	if err := os.WriteFile(envFile, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	envDriver, err := NewEnvironmentDriver(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fallback := testDriver{vals: map[string]string{"FIG_LOOKUP_USER": "admin"}}
+	conf := New(envDriver, fallback)
+
+	t.Run("Lookup reports file and line for .env values", func(t *testing.T) {
+		val, src, err := conf.Lookup("FIG_LOOKUP_PORT")
+		if err != nil {
+			t.Fatalf("unexpected error from Lookup: %s", err)
+		}
+		if val != "5432" {
+			t.Errorf("unexpected value %s from Lookup", val)
+		}
+		exp := Source{Driver: "env", File: envFile, Line: 4}
+		if src != exp {
+			t.Errorf("unexpected source %+v, expected %+v", src, exp)
+		}
+	})
+
+	t.Run("Lookup reports driver for values without a file", func(t *testing.T) {
+		_, src, err := conf.Lookup("FIG_LOOKUP_USER")
+		if err != nil {
+			t.Fatalf("unexpected error from Lookup: %s", err)
+		}
+		if src != (Source{Driver: "test"}) {
+			t.Errorf("unexpected source %+v", src)
+		}
+	})
+
+	t.Run("real environment variables have no file", func(t *testing.T) {
+		t.Setenv("FIG_LOOKUP_HOST", "db.prod")
+		_, src, err := conf.Lookup("FIG_LOOKUP_HOST")
+		if err != nil {
+			t.Fatalf("unexpected error from Lookup: %s", err)
+		}
+		if src.String() != "env" {
+			t.Errorf("unexpected source %s", src)
+		}
+	})
+
+	t.Run("Unmarshal fills sources", func(t *testing.T) {
+		var ts struct {
+			Host    string `fig:"FIG_LOOKUP_HOST"`
+			User    string `fig:"FIG_LOOKUP_USER"`
+			Timeout string `fig:"FIG_LOOKUP_TIMEOUT" default:"5s"`
+			Missing string `fig:"FIG_LOOKUP_MISSING"`
+		}
+		sources := map[string]Source{}
+		if err := conf.Unmarshal(&ts, WithSources(sources)); err != nil {
+			t.Fatalf("unexpected error from Unmarshal: %s", err)
+		}
+
+		exp := map[string]Source{
+			"FIG_LOOKUP_HOST":    {Driver: "env", File: envFile, Line: 2},
+			"FIG_LOOKUP_USER":    {Driver: "test"},
+			"FIG_LOOKUP_TIMEOUT": {Driver: "default"},
+		}
+		if !reflect.DeepEqual(sources, exp) {
+			t.Errorf("unexpected sources %v, expected %v", sources, exp)
+		}
+		if sources["FIG_LOOKUP_HOST"].String() != "env ("+envFile+":2)" {
+			t.Errorf("unexpected source string %s", sources["FIG_LOOKUP_HOST"])
+		}
+	})
 }
@@ -0,0 +1,124 @@
+// Package figtest provides helpers for testing code that reads configuration with fig.
+package figtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nate-anderson/fig/v2"
+)
+
+// Config is a fig.Config backed by in-memory values that can be overridden for a single test
+type Config struct {
+	fig.Config
+	t       testing.TB
+	values  fig.MapDriver
+	drivers []fig.Driver
+}
+
+// New builds a Config for a test from vals, which is copied. Any drivers passed are consulted
+// after vals, e.g. to fall back to the real environment.
+func New(t testing.TB, vals map[string]string, drivers ...fig.Driver) *Config {
+	t.Helper()
+
+	values := fig.MapDriver{}
+	for k, v := range vals {
+		values[k] = v
+	}
+
+	return &Config{
+		Config:  fig.New(append([]fig.Driver{values}, drivers...)...),
+		t:       t,
+		values:  values,
+		drivers: drivers,
+	}
+}
+
+// For returns a Config for a subtest starting from a copy of c's current values, so overrides
+// made with it never affect c and parallel subtests don't share state. Like New, it is built from
+// the values and drivers only, so options applied to c.Config must be applied to it again.
+func (c *Config) For(t testing.TB) *Config {
+	t.Helper()
+	return New(t, c.values, c.drivers...)
+}
+
+// Set overrides key for the rest of the test, like testing.T.Setenv. The previous value is
+// restored when the test and its subtests complete.
+func (c *Config) Set(key, value string) {
+	c.t.Helper()
+	c.restoreOnCleanup(key)
+	c.values[key] = value
+}
+
+// Unset removes key for the rest of the test. The previous value is restored when the test and
+// its subtests complete.
+func (c *Config) Unset(key string) {
+	c.t.Helper()
+	c.restoreOnCleanup(key)
+	delete(c.values, key)
+}
+
+// register a cleanup restoring key to its current state
+func (c *Config) restoreOnCleanup(key string) {
+	prev, existed := c.values[key]
+	c.t.Cleanup(func() {
+		if existed {
+			c.values[key] = prev
+		} else {
+			delete(c.values, key)
+		}
+	})
+}
+
+// MustUnmarshal unmarshals into dest, failing the test on error
+func (c *Config) MustUnmarshal(dest interface{}) {
+	c.t.Helper()
+	MustUnmarshal(c.t, c.Config, dest)
+}
+
+// AssertUnmarshalError asserts that unmarshaling into dest fails for each of keys
+func (c *Config) AssertUnmarshalError(dest interface{}, keys ...string) {
+	c.t.Helper()
+	AssertUnmarshalError(c.t, c.Config, dest, keys...)
+}
+
+// MustUnmarshal unmarshals conf into dest, failing the test on error
+func MustUnmarshal(t testing.TB, conf fig.Config, dest interface{}) {
+	t.Helper()
+	if err := conf.Unmarshal(dest); err != nil {
+		t.Fatalf("unexpected error from Unmarshal: %s", err)
+	}
+}
+
+// AssertUnmarshalError asserts that unmarshaling conf into dest fails, and that the error
+// includes a fig.FieldError for each of keys. With no keys, any error passes.
+func AssertUnmarshalError(t testing.TB, conf fig.Config, dest interface{}, keys ...string) {
+	t.Helper()
+
+	err := conf.Unmarshal(dest)
+	if err == nil {
+		t.Errorf("expected error from Unmarshal, got nil")
+		return
+	}
+
+	var fieldErrs fig.UnmarshalErrors
+	if !errors.As(err, &fieldErrs) {
+		if len(keys) > 0 {
+			t.Errorf("expected field errors for %v from Unmarshal, got %s", keys, err)
+		}
+		return
+	}
+
+	for _, key := range keys {
+		found := false
+		for _, fieldErr := range fieldErrs {
+			if fieldErr.Key == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("expected Unmarshal error for key %s, got %s", key, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package figtest
+
+import (
+	"fmt"
+	"testing"
+)
+
+type appConfig struct {
+	Host string `fig:"DB_HOST" required:"true"`
+	Port int    `fig:"DB_PORT" default:"5432"`
+}
+
+func TestNew(t *testing.T) {
+	vals := map[string]string{"DB_HOST": "db.internal"}
+	conf := New(t, vals)
+
+	var cfg appConfig
+	conf.MustUnmarshal(&cfg)
+	if cfg.Host != "db.internal" || cfg.Port != 5432 {
+		t.Errorf("unexpected values %+v", cfg)
+	}
+
+	conf.Set("DB_PORT", "6432")
+	if vals["DB_PORT"] != "" {
+		t.Errorf("Set modified the map passed to New")
+	}
+}
+
+func TestSetRestoresOnCleanup(t *testing.T) {
+	conf := New(t, map[string]string{"DB_HOST": "db.internal"})
+
+	t.Run("override", func(t *testing.T) {
+		sub := conf.For(t)
+		sub.Set("DB_HOST", "override")
+		sub.Set("DB_PORT", "6432")
+		if host := sub.MustGetString("DB_HOST"); host != "override" {
+			t.Errorf("expected override, got %s", host)
+		}
+	})
+
+	if host := conf.MustGetString("DB_HOST"); host != "db.internal" {
+		t.Errorf("expected DB_HOST to be restored, got %s", host)
+	}
+	if _, err := conf.GetString("DB_PORT"); err == nil {
+		t.Errorf("expected DB_PORT to be removed after cleanup")
+	}
+
+	t.Run("unset", func(t *testing.T) {
+		sub := conf.For(t)
+		sub.Unset("DB_HOST")
+		if _, err := sub.GetString("DB_HOST"); err == nil {
+			t.Errorf("expected DB_HOST to be unset")
+		}
+	})
+
+	if host := conf.MustGetString("DB_HOST"); host != "db.internal" {
+		t.Errorf("expected DB_HOST to be restored after Unset, got %s", host)
+	}
+}
+
+func TestAssertUnmarshalError(t *testing.T) {
+	conf := New(t, map[string]string{"DB_PORT": "http"})
+
+	var cfg appConfig
+	conf.AssertUnmarshalError(&cfg, "DB_HOST", "DB_PORT")
+
+	t.Run("fails when a key has no error", func(t *testing.T) {
+		fake := &recordingTB{}
+		AssertUnmarshalError(fake, conf.Config, &cfg, "MISSING")
+		if len(fake.failures) != 1 {
+			t.Errorf("expected assertion to fail for key without an error, got %q", fake.failures)
+		}
+	})
+}
+
+func TestForParallel(t *testing.T) {
+	conf := New(t, map[string]string{"DB_HOST": "db.internal"})
+
+	for _, host := range []string{"a.internal", "b.internal", "c.internal"} {
+		host := host
+		t.Run(host, func(t *testing.T) {
+			t.Parallel()
+			sub := conf.For(t)
+			sub.Set("DB_HOST", host)
+			if got := sub.MustGetString("DB_HOST"); got != host {
+				t.Errorf("expected %s, got %s", host, got)
+			}
+		})
+	}
+
+	if host := conf.MustGetString("DB_HOST"); host != "db.internal" {
+		t.Errorf("expected subtests not to modify the parent, got %s", host)
+	}
+}
+
+// recordingTB records failures instead of failing the test, for testing assertions
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Fatalf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
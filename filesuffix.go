@@ -0,0 +1,150 @@
+package fig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// FileSuffixDriver wraps another driver to follow the Docker convention of reading a value from
+// the file named by KEY_FILE when KEY itself is not set. For example, with
+// DB_PASSWORD_FILE=/run/secrets/db-password, DB_PASSWORD is read from that file.
+//
+// Failing to read the named file is an error rather than ErrConfigNotFound, so a missing or
+// unreadable mount is noticed.
+type FileSuffixDriver struct {
+	driver  Driver
+	options fileSuffixOptions
+}
+
+// FileSuffixOption configures a FileSuffixDriver
+type FileSuffixOption func(*fileSuffixOptions)
+
+type fileSuffixOptions struct {
+	suffix      string
+	trim        bool
+	maxSize     int64
+	allowedDirs []string
+}
+
+// FileSuffix changes the suffix appended to keys, "_FILE" by default
+func FileSuffix(suffix string) FileSuffixOption {
+	return func(o *fileSuffixOptions) {
+		o.suffix = suffix
+	}
+}
+
+// FileTrim controls whether trailing newlines are trimmed from file contents, true by default
+func FileTrim(trim bool) FileSuffixOption {
+	return func(o *fileSuffixOptions) {
+		o.trim = trim
+	}
+}
+
+// FileMaxSize limits the size of files that will be read, DefaultMaxFileSize by default
+func FileMaxSize(bytes int64) FileSuffixOption {
+	return func(o *fileSuffixOptions) {
+		o.maxSize = bytes
+	}
+}
+
+// FileAllowedDirs restricts files to those inside dirs, after resolving symlinks. By default any
+// file may be read.
+func FileAllowedDirs(dirs ...string) FileSuffixOption {
+	return func(o *fileSuffixOptions) {
+		o.allowedDirs = dirs
+	}
+}
+
+// WithFileSuffix wraps driver to read KEY from the file named by KEY_FILE when KEY is not set
+func WithFileSuffix(driver Driver, opts ...FileSuffixOption) FileSuffixDriver {
+	options := fileSuffixOptions{suffix: "_FILE", trim: true, maxSize: DefaultMaxFileSize}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return FileSuffixDriver{driver: driver, options: options}
+}
+
+// Get returns the wrapped driver's value for key, or the contents of the file named by key's
+// _FILE variant
+func (d FileSuffixDriver) Get(key string) (string, error) {
+	val, err := d.driver.Get(key)
+	if !errors.Is(err, ErrConfigNotFound) {
+		return val, err
+	}
+
+	path, err := d.driver.Get(key + d.options.suffix)
+	if err != nil {
+		return "", err
+	}
+
+	if err := d.checkAllowed(path); err != nil {
+		return "", err
+	}
+	return readValueFile(path, d.options.maxSize, d.options.trim)
+}
+
+// Name returns the wrapped driver's name
+func (d FileSuffixDriver) Name() string {
+	return d.driver.Name()
+}
+
+// Locate returns the file named by key's _FILE variant if the value is read from it, and
+// otherwise defers to the wrapped driver
+func (d FileSuffixDriver) Locate(key string) (string, int, bool) {
+	if _, err := d.driver.Get(key); !errors.Is(err, ErrConfigNotFound) {
+		if locator, ok := d.driver.(Locator); ok {
+			return locator.Locate(key)
+		}
+		return "", 0, false
+	}
+
+	path, err := d.driver.Get(key + d.options.suffix)
+	if err != nil {
+		return "", 0, false
+	}
+	return path, 0, true
+}
+
+// Watch defers to the wrapped driver if it implements Watcher, and otherwise blocks until ctx is
+// done. Changes to the contents of _FILE files are not detected.
+func (d FileSuffixDriver) Watch(ctx context.Context, changed func()) error {
+	return watchDriver(ctx, d.driver, changed)
+}
+
+func (d FileSuffixDriver) watchable() bool {
+	return isWatchable(d.driver)
+}
+
+// checkAllowed ensures path resolves to a file inside one of the allowed directories
+func (d FileSuffixDriver) checkAllowed(path string) error {
+	if len(d.options.allowedDirs) == 0 {
+		return nil
+	}
+
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return err
+	}
+	for _, dir := range d.options.allowedDirs {
+		resolvedDir, err := resolvePath(dir)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(resolvedDir, resolved); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("file %s is not inside an allowed directory", path)
+}
+
+// resolve a path to an absolute path without symlinks
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
@@ -0,0 +1,89 @@
+package fig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSuffixDriver(t *testing.T) {
+	secrets := t.TempDir()
+	passwordFile := filepath.Join(secrets, "db-password")
+	if err := os.WriteFile(passwordFile, []byte("hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	outside := writeTestFile(t, "outside", "nope")
+
+	env := MapDriver{
+		"DB_USER":          "admin",
+		"DB_USER_FILE":     passwordFile,
+		"DB_PASSWORD_FILE": passwordFile,
+		"API_KEY_FILE":     filepath.Join(secrets, "missing"),
+		"OUTSIDE_FILE":     outside,
+	}
+	driver := WithFileSuffix(env)
+
+	t.Run("direct values take precedence", func(t *testing.T) {
+		val, err := driver.Get("DB_USER")
+		if err != nil || val != "admin" {
+			t.Errorf("expected admin, got %q (%v)", val, err)
+		}
+	})
+
+	t.Run("values are read from _FILE paths", func(t *testing.T) {
+		val, err := driver.Get("DB_PASSWORD")
+		if err != nil || val != "hunter2" {
+			t.Errorf("expected hunter2, got %q (%v)", val, err)
+		}
+
+		_, src, err := New(driver).Lookup("DB_PASSWORD")
+		if err != nil || src.File != passwordFile || src.Driver != "map" {
+			t.Errorf("unexpected source %+v (%v)", src, err)
+		}
+	})
+
+	t.Run("keys without either variable are not found", func(t *testing.T) {
+		if _, err := driver.Get("DB_HOST"); !errors.Is(err, ErrConfigNotFound) {
+			t.Errorf("expected ErrConfigNotFound, got %v", err)
+		}
+	})
+
+	t.Run("unreadable files are driver errors", func(t *testing.T) {
+		_, err := New(driver).GetString("API_KEY")
+		var driverErr *DriverError
+		if !errors.As(err, &driverErr) || errors.Is(err, ErrConfigNotFound) {
+			t.Errorf("expected DriverError for missing file, got %v", err)
+		}
+	})
+
+	t.Run("trimming can be disabled", func(t *testing.T) {
+		val, err := WithFileSuffix(env, FileTrim(false)).Get("DB_PASSWORD")
+		if err != nil || val != "hunter2\n" {
+			t.Errorf("expected untrimmed contents, got %q (%v)", val, err)
+		}
+	})
+
+	t.Run("files over the size limit are an error", func(t *testing.T) {
+		if _, err := WithFileSuffix(env, FileMaxSize(3)).Get("DB_PASSWORD"); err == nil {
+			t.Errorf("expected size limit error")
+		}
+	})
+
+	t.Run("files outside allowed directories are an error", func(t *testing.T) {
+		restricted := WithFileSuffix(env, FileAllowedDirs(secrets))
+		if val, err := restricted.Get("DB_PASSWORD"); err != nil || val != "hunter2" {
+			t.Errorf("expected file in allowed directory to be read, got %q (%v)", val, err)
+		}
+		if _, err := restricted.Get("OUTSIDE"); err == nil || errors.Is(err, ErrConfigNotFound) {
+			t.Errorf("expected error for file outside allowed directories, got %v", err)
+		}
+	})
+
+	t.Run("custom suffix", func(t *testing.T) {
+		custom := WithFileSuffix(MapDriver{"DB_PASSWORD_PATH": passwordFile}, FileSuffix("_PATH"))
+		if val, err := custom.Get("DB_PASSWORD"); err != nil || val != "hunter2" {
+			t.Errorf("expected hunter2, got %q (%v)", val, err)
+		}
+	})
+}
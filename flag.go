@@ -0,0 +1,231 @@
+package fig
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FlagDriver supports reading from command line flags. Only flags explicitly set on the command
+// line are returned, so unset flags fall through to later drivers:
+//
+//	conf := fig.New(flagDriver, envDriver) // --db-host overrides DB_HOST
+//
+// By default the flag db-host supplies the config key DB_HOST. Use FlagKeys to change the mapping.
+type FlagDriver struct {
+	fs      *flag.FlagSet
+	args    map[string]string
+	keys    map[string]string
+	options flagOptions
+}
+
+// FlagOption configures a FlagDriver
+type FlagOption func(*flagOptions)
+
+type flagOptions struct {
+	toKey   func(flagName string) string
+	toFlag  func(key string) string
+	parsers map[reflect.Type]parseFunc
+}
+
+var defaultFlagOptions = flagOptions{
+	toKey:  FlagNameToKey,
+	toFlag: KeyToFlagName,
+}
+
+// FlagKeys sets the functions that map flag names to config keys and, for RegisterFlags, config
+// keys to flag names
+func FlagKeys(toKey func(flagName string) string, toFlag func(key string) string) FlagOption {
+	return func(o *flagOptions) {
+		if toKey != nil {
+			o.toKey = toKey
+		}
+		if toFlag != nil {
+			o.toFlag = toFlag
+		}
+	}
+}
+
+// FlagConfig makes RegisterFlags use the parsers added to c with WithParser, so fields of those
+// types are registered as single flags rather than walked as nested structs
+func FlagConfig(c Config) FlagOption {
+	return func(o *flagOptions) {
+		o.parsers = c.parsers
+	}
+}
+
+// FlagNameToKey is the default flag name mapping, e.g. db-host to DB_HOST
+func FlagNameToKey(flagName string) string {
+	return strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// KeyToFlagName is the default config key mapping, e.g. DB_HOST to db-host
+func KeyToFlagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
+
+// NewFlagDriver reads the flags set on fs. fs may be parsed before or after the driver is created.
+func NewFlagDriver(fs *flag.FlagSet, opts ...FlagOption) FlagDriver {
+	return FlagDriver{fs: fs, options: applyFlagOptions(opts)}
+}
+
+// NewArgsDriver parses command line arguments, such as os.Args[1:], without a flag.FlagSet.
+// Flags may be written -name, --name, --name=value or --name value. A flag followed by another
+// flag or by nothing is treated as the boolean "true", while a following negative number, as in
+// --offset -5, is the flag's value. Parsing stops at "--".
+func NewArgsDriver(args []string, opts ...FlagOption) (FlagDriver, error) {
+	values := map[string]string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			continue
+		}
+
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if name == "" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, "=") {
+			return FlagDriver{}, fmt.Errorf("bad flag syntax: %s", arg)
+		}
+
+		if name, value, ok := strings.Cut(name, "="); ok {
+			values[name] = value
+			continue
+		}
+
+		if i+1 < len(args) && (!strings.HasPrefix(args[i+1], "-") || isNegativeNumber(args[i+1])) {
+			values[name] = args[i+1]
+			i++
+		} else {
+			values[name] = "true"
+		}
+	}
+
+	return FlagDriver{args: values, options: applyFlagOptions(opts)}, nil
+}
+
+// isNegativeNumber reports whether arg looks like a negative number, such as -5, -.5 or -1.5s,
+// rather than a flag
+func isNegativeNumber(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-' && (arg[1] == '.' || (arg[1] >= '0' && arg[1] <= '9'))
+}
+
+func applyFlagOptions(opts []FlagOption) flagOptions {
+	options := defaultFlagOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// Get returns the value of the set flag that maps to key, or ErrConfigNotFound
+func (d FlagDriver) Get(key string) (string, error) {
+	var val string
+	found := false
+	d.visit(func(name, value string) {
+		if d.keyFor(name) == key {
+			val, found = value, true
+		}
+	})
+
+	if !found {
+		return "", ErrConfigNotFound
+	}
+	return val, nil
+}
+
+func (d FlagDriver) Name() string {
+	return "flag"
+}
+
+// call fn for each flag explicitly set
+func (d FlagDriver) visit(fn func(name, value string)) {
+	if d.fs != nil {
+		d.fs.Visit(func(f *flag.Flag) {
+			fn(f.Name, f.Value.String())
+		})
+		return
+	}
+	for name, value := range d.args {
+		fn(name, value)
+	}
+}
+
+// keyFor returns the config key for a flag name
+func (d FlagDriver) keyFor(name string) string {
+	if key, ok := d.keys[name]; ok {
+		return key
+	}
+	return d.options.toKey(name)
+}
+
+// RegisterFlags defines a flag on fs for every `fig` tagged field of dest, which should be a
+// pointer to a struct, and returns a FlagDriver that reads them. Flag names are derived from
+// config keys, e.g. DB_HOST becomes db-host, and the `usage` tag sets the flag's usage text.
+// Bool fields are registered as boolean flags. Flags already defined on fs are left as they are.
+// Pass FlagConfig to register fields of types with a WithParser parser as single flags.
+//
+//	flagDriver, err := fig.RegisterFlags(flag.CommandLine, &appConfig)
+//	flag.Parse()
+//	err = fig.New(flagDriver, envDriver).Unmarshal(&appConfig)
+func RegisterFlags(fs *flag.FlagSet, dest interface{}, opts ...FlagOption) (FlagDriver, error) {
+	under, err := structValue(dest)
+	if err != nil {
+		return FlagDriver{}, err
+	}
+
+	driver := FlagDriver{fs: fs, keys: map[string]string{}, options: applyFlagOptions(opts)}
+	Config{parsers: driver.options.parsers}.walkStruct(under, "", "", func(f configField) bool {
+		name := driver.options.toFlag(f.key)
+		driver.keys[name] = f.key
+		if fs.Lookup(name) != nil {
+			return false
+		}
+
+		usage, ok := f.field.Tag.Lookup(usageTag)
+		if !ok {
+			usage = "sets " + f.key
+		}
+		value := &flagValue{
+			value:  f.field.Tag.Get(defaultTag),
+			isBool: indirectKind(f.field.Type) == reflect.Bool,
+		}
+		fs.Var(value, name, usage)
+		return false
+	})
+
+	return driver, nil
+}
+
+// flagValue is a flag.Value holding a raw string for fig to parse
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+// IsBoolFlag lets boolean flags be set without a value, e.g. --debug
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// indirectKind returns the kind of t, or of its element if t is a pointer
+func indirectKind(t reflect.Type) reflect.Kind {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem().Kind()
+	}
+	return t.Kind()
+}
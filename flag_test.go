@@ -0,0 +1,164 @@
+package fig
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFlagDriver(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("db-host", "localhost", "database host")
+	fs.Int("db-port", 5432, "database port")
+	driver := NewFlagDriver(fs)
+
+	if err := fs.Parse([]string{"--db-host", "db.internal"}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("set flags are returned by mapped key", func(t *testing.T) {
+		val, err := driver.Get("DB_HOST")
+		if err != nil || val != "db.internal" {
+			t.Errorf("expected db.internal, got %s (%v)", val, err)
+		}
+	})
+
+	t.Run("unset flags return ErrConfigNotFound", func(t *testing.T) {
+		if _, err := driver.Get("DB_PORT"); !errors.Is(err, ErrConfigNotFound) {
+			t.Errorf("expected ErrConfigNotFound for unset flag, got %v", err)
+		}
+	})
+
+	t.Run("flags take precedence over later drivers", func(t *testing.T) {
+		env := MapDriver{"DB_HOST": "env.internal", "DB_PORT": "6432"}
+		conf := New(driver, env)
+		if host := conf.MustGetString("DB_HOST"); host != "db.internal" {
+			t.Errorf("expected flag to override env, got %s", host)
+		}
+		if port := conf.MustGetInt("DB_PORT"); port != 6432 {
+			t.Errorf("expected unset flag to fall through to env, got %d", port)
+		}
+	})
+
+	t.Run("custom key mapping", func(t *testing.T) {
+		dotted := NewFlagDriver(fs, FlagKeys(func(name string) string {
+			return strings.ReplaceAll(name, "-", ".")
+		}, nil))
+		val, err := dotted.Get("db.host")
+		if err != nil || val != "db.internal" {
+			t.Errorf("expected db.internal from custom mapping, got %s (%v)", val, err)
+		}
+	})
+}
+
+func TestArgsDriver(t *testing.T) {
+	driver, err := NewArgsDriver([]string{"serve", "--db-host=db.internal", "-db-port", "6432", "--offset", "-5", "--debug", "--verbose", "--", "--ignored=true"})
+	if err != nil {
+		t.Fatalf("unexpected error parsing args: %s", err)
+	}
+
+	cases := map[string]string{
+		"DB_HOST": "db.internal",
+		"DB_PORT": "6432",
+		"OFFSET":  "-5",
+		"DEBUG":   "true",
+		"VERBOSE": "true",
+	}
+	for key, exp := range cases {
+		val, err := driver.Get(key)
+		if err != nil || val != exp {
+			t.Errorf("expected %s for %s, got %s (%v)", exp, key, val, err)
+		}
+	}
+
+	if _, err := driver.Get("IGNORED"); !errors.Is(err, ErrConfigNotFound) {
+		t.Errorf("expected arguments after -- to be ignored, got %v", err)
+	}
+
+	if _, err := NewArgsDriver([]string{"---bad"}); err == nil {
+		t.Errorf("expected error for bad flag syntax")
+	}
+}
+
+func TestRegisterFlags(t *testing.T) {
+	type appConfig struct {
+		Host  string `fig:"DB_HOST" usage:"database host"`
+		Port  int    `fig:"DB_PORT" default:"5432"`
+		Debug bool   `fig:"DEBUG"`
+		Cache struct {
+			TTL string `fig:"TTL"`
+		} `prefix:"CACHE_"`
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var cfg appConfig
+	driver, err := RegisterFlags(fs, &cfg)
+	if err != nil {
+		t.Fatalf("unexpected error registering flags: %s", err)
+	}
+
+	t.Run("flags are registered from tags", func(t *testing.T) {
+		for name, usage := range map[string]string{
+			"db-host":   "database host",
+			"db-port":   "sets DB_PORT",
+			"debug":     "sets DEBUG",
+			"cache-ttl": "sets CACHE_TTL",
+		} {
+			f := fs.Lookup(name)
+			if f == nil {
+				t.Errorf("flag %s was not registered", name)
+				continue
+			}
+			if f.Usage != usage {
+				t.Errorf("flag %s has usage %q, expected %q", name, f.Usage, usage)
+			}
+		}
+		if def := fs.Lookup("db-port").DefValue; def != "5432" {
+			t.Errorf("expected default tag as flag default, got %s", def)
+		}
+	})
+
+	if err := fs.Parse([]string{"--db-host", "db.internal", "--debug", "--cache-ttl=1m"}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("registered flags unmarshal", func(t *testing.T) {
+		env := MapDriver{"DB_HOST": "env.internal", "DB_PORT": "6432"}
+		if err := New(driver, env).Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error from Unmarshal: %s", err)
+		}
+		if cfg.Host != "db.internal" || cfg.Port != 6432 || !cfg.Debug || cfg.Cache.TTL != "1m" {
+			t.Errorf("unexpected values %+v", cfg)
+		}
+	})
+	t.Run("FlagConfig registers parsed types as single flags", func(t *testing.T) {
+		type point struct{ X, Y int }
+		parsePoint := func(value string) (point, error) {
+			var p point
+			_, err := fmt.Sscanf(value, "%d,%d", &p.X, &p.Y)
+			return p, err
+		}
+		var dest struct {
+			Origin point `fig:"ORIGIN"`
+		}
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		driver, err := RegisterFlags(fs, &dest, FlagConfig(WithParser(Config{}, parsePoint)))
+		if err != nil {
+			t.Fatalf("unexpected error registering flags: %s", err)
+		}
+		if err := fs.Parse([]string{"--origin", "3,-4"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := WithParser(New(driver), parsePoint).Unmarshal(&dest); err != nil {
+			t.Fatalf("unexpected error from Unmarshal: %s", err)
+		}
+		if dest.Origin != (point{3, -4}) {
+			t.Errorf("unexpected value %+v", dest.Origin)
+		}
+	})
+}
@@ -1,5 +1,9 @@
 module github.com/nate-anderson/fig/v2
 
-go 1.17
+go 1.20
 
-require github.com/joho/godotenv v1.3.0
+require (
+	github.com/joho/godotenv v1.3.0
+	github.com/pelletier/go-toml/v2 v2.0.9
+	gopkg.in/yaml.v3 v3.0.1
+)
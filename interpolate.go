@@ -0,0 +1,158 @@
+package fig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WithInterpolation returns a copy of c that expands references to other keys in config values.
+// References are resolved through all of c's drivers, so a value from a .env file can refer to
+// one from the real environment. The supported forms are:
+//
+//	${VAR}           the value of VAR, or empty if VAR is not set
+//	${VAR:-default}  the value of VAR, or default if VAR is unset or empty
+//	${VAR:?message}  the value of VAR, or an InterpolationError with message if VAR is unset or empty
+//	$$               a literal $
+//
+// Defaults may contain references of their own, as may `default` tags. A $ not followed by { or $
+// is kept as written. References that form a cycle are an InterpolationError wrapping
+// ErrInterpolationCycle.
+//
+// godotenv expands $VAR and ${VAR} in unquoted and double-quoted values using only the file's own
+// keys, so single-quote .env values that should be expanded by the Config instead.
+func WithInterpolation(c Config) Config {
+	c.interpolate = true
+	return c
+}
+
+// expand the references in value, which was read for key. stack holds the keys being expanded,
+// to detect cycles.
+func (c Config) expand(key, value string, stack []string) (string, error) {
+	if !strings.Contains(value, "$") {
+		return value, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i == len(value)-1 {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		switch value[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := closingBrace(value, i+2)
+			if end < 0 {
+				return "", &InterpolationError{Key: key, Ref: value[i+2:], Cause: errors.New("unterminated reference")}
+			}
+			expanded, err := c.expandRef(key, value[i+2:end], stack)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(expanded)
+			i = end
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+// expandRef expands the contents of a ${...} reference in key's value
+func (c Config) expandRef(key, ref string, stack []string) (string, error) {
+	name, op, arg := splitRef(ref)
+
+	for _, k := range stack {
+		if k == name {
+			chain := strings.Join(append(stack, name), " -> ")
+			return "", &InterpolationError{Key: key, Ref: name, Cause: fmt.Errorf("%w: %s", ErrInterpolationCycle, chain)}
+		}
+	}
+
+	val, _, err := c.lookupRaw(name)
+	if err == nil {
+		val, err = c.expand(name, val, append(stack[:len(stack):len(stack)], name))
+		if err != nil {
+			return "", err
+		}
+	} else if !errors.Is(err, ErrConfigNotFound) {
+		return "", &InterpolationError{Key: key, Ref: name, Cause: err}
+	}
+
+	if val != "" {
+		return val, nil
+	}
+	switch op {
+	case ":-":
+		return c.expand(key, arg, stack)
+	case ":?":
+		if arg == "" {
+			arg = "not set"
+		}
+		return "", &InterpolationError{Key: key, Ref: name, Cause: errors.New(arg)}
+	}
+	return "", nil
+}
+
+// splitRef splits the contents of a ${...} reference into the referenced key, the :- or :?
+// operator and its argument
+func splitRef(ref string) (name, op, arg string) {
+	if i := strings.Index(ref, ":"); i >= 0 && i+1 < len(ref) && (ref[i+1] == '-' || ref[i+1] == '?') {
+		return ref[:i], ref[i : i+2], ref[i+2:]
+	}
+	return ref, "", ""
+}
+
+// references adds the keys value refers to to seen, including keys referred to by their values
+// and by defaults, whether or not the defaults would be used
+func (c Config) references(value string, seen map[string]bool) {
+	for i := 0; i < len(value)-1; i++ {
+		if value[i] != '$' {
+			continue
+		}
+		if value[i+1] == '$' {
+			i++
+			continue
+		}
+		if value[i+1] != '{' {
+			continue
+		}
+
+		end := closingBrace(value, i+2)
+		if end < 0 {
+			return
+		}
+		name, _, arg := splitRef(value[i+2 : end])
+		if !seen[name] {
+			seen[name] = true
+			if val, _, err := c.lookupRaw(name); err == nil {
+				c.references(val, seen)
+			}
+		}
+		c.references(arg, seen)
+		i = end
+	}
+}
+
+// closingBrace returns the index of the } closing a reference whose contents start at start,
+// allowing for nested references in defaults, or -1
+func closingBrace(value string, start int) int {
+	depth := 1
+	for i := start; i < len(value); i++ {
+		switch {
+		case value[i] == '$' && i+1 < len(value) && value[i+1] == '{':
+			depth++
+			i++
+		case value[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,44 @@
+package fig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// JSONDriver supports reading from a JSON file. Keys are paths into the document, such as
+// "db.host" or "servers[0].port". Numbers and bools are returned as written.
+type JSONDriver struct {
+	treeFileDriver
+}
+
+// NewJSONDriver reads and parses the JSON file at path. A malformed file is an error.
+func NewJSONDriver(path string) (JSONDriver, error) {
+	driver, err := loadTreeFile(path, "JSON", parseJSON)
+	if err != nil {
+		return JSONDriver{}, err
+	}
+	return JSONDriver{treeFileDriver: driver}, nil
+}
+
+// parse a JSON document, keeping numbers in their original form. Anything after the document
+// other than whitespace is an error.
+func parseJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var root interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unexpected data after top-level value at offset %d", decoder.InputOffset())
+	}
+	return root, nil
+}
+
+func (d JSONDriver) Name() string {
+	return "json"
+}
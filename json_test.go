@@ -0,0 +1,166 @@
+package fig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// write contents to a file in a temporary directory and return its path
+func writeTestFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const testJSON = `{
+	"db": {
+		"host": "db.internal",
+		"port": 5432,
+		"ratio": 0.75,
+		"tls": true,
+		"password": null
+	},
+	"servers": [
+		{"name": "a", "port": 8080},
+		{"name": "b", "port": 8081}
+	],
+	"origins": ["a.com", "b.com"],
+	"labels": {"env": "prod", "team": "core"},
+	"log.level": "debug",
+	"big": 12345678901234567890,
+	"tags": ["a,b", "c"]
+}`
+
+func TestJSONDriver(t *testing.T) {
+	driver, err := NewJSONDriver(writeTestFile(t, "config.json", testJSON))
+	if err != nil {
+		t.Fatalf("unexpected error creating JSON driver: %s", err)
+	}
+
+	t.Run("paths resolve to rendered values", func(t *testing.T) {
+		cases := map[string]string{
+			"db.host":         "db.internal",
+			"db.port":         "5432",
+			"db.ratio":        "0.75",
+			"db.tls":          "true",
+			"servers[1].port": "8081",
+			"servers.0.name":  "a",
+			"origins":         "a.com,b.com",
+			"labels":          "env:prod,team:core",
+			"log.level":       "debug",
+			"big":             "12345678901234567890",
+			"servers[0]":      "name:a,port:8080",
+			"servers":         `[{"name":"a","port":8080},{"name":"b","port":8081}]`,
+			"tags[0]":         "a,b",
+		}
+		for key, exp := range cases {
+			val, err := driver.Get(key)
+			if err != nil {
+				t.Errorf("unexpected error for key %s: %s", key, err)
+			}
+			if val != exp {
+				t.Errorf("unexpected value %s for key %s, expected %s", val, key, exp)
+			}
+		}
+	})
+
+	t.Run("lists with elements containing commas are an error", func(t *testing.T) {
+		var driverErr *DriverError
+		if _, err := New(driver).GetString("tags"); !errors.As(err, &driverErr) {
+			t.Errorf("expected DriverError, got %v", err)
+		}
+	})
+
+	t.Run("missing paths return ErrConfigNotFound", func(t *testing.T) {
+		for _, key := range []string{"db.user", "servers[2].port", "servers[x]", "db.host.name", "db.password", "DB_HOST"} {
+			if _, err := driver.Get(key); !errors.Is(err, ErrConfigNotFound) {
+				t.Errorf("expected ErrConfigNotFound for key %s, got %v", key, err)
+			}
+		}
+	})
+
+	t.Run("Unmarshal reads typed values", func(t *testing.T) {
+		var ts struct {
+			Port    uint16            `fig:"db.port"`
+			TLS     bool              `fig:"db.tls"`
+			Origins []string          `fig:"origins"`
+			Labels  map[string]string `fig:"labels"`
+			Server  struct {
+				Port int `fig:"port"`
+			} `prefix:"servers[1]."`
+		}
+		if err := New(driver).Unmarshal(&ts); err != nil {
+			t.Fatalf("unexpected error from Unmarshal: %s", err)
+		}
+		if ts.Port != 5432 || !ts.TLS || len(ts.Origins) != 2 || ts.Labels["team"] != "core" || ts.Server.Port != 8081 {
+			t.Errorf("unmarshaled unexpected values %+v", ts)
+		}
+	})
+
+	t.Run("falls back to later drivers", func(t *testing.T) {
+		conf := New(driver, testDriver{vals: map[string]string{"db.user": "admin"}})
+		val, src, err := conf.Lookup("db.user")
+		if err != nil || val != "admin" || src.Driver != "test" {
+			t.Errorf("expected fallback to second driver, got %s from %s (%v)", val, src, err)
+		}
+
+		_, src, _ = conf.Lookup("db.host")
+		if src.Driver != "json" || src.File == "" {
+			t.Errorf("expected JSON source with file, got %+v", src)
+		}
+	})
+
+	t.Run("malformed files fail construction", func(t *testing.T) {
+		if _, err := NewJSONDriver(writeTestFile(t, "bad.json", `{"db": `)); err == nil {
+			t.Errorf("expected error for malformed JSON")
+		}
+		if _, err := NewJSONDriver(writeTestFile(t, "trailing.json", `{"a": 1} garbage {`)); err == nil {
+			t.Errorf("expected error for data after the JSON document")
+		}
+		if _, err := NewJSONDriver(writeTestFile(t, "whitespace.json", "{\"a\": 1}\n\n")); err != nil {
+			t.Errorf("unexpected error for trailing whitespace: %s", err)
+		}
+		if _, err := NewJSONDriver(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+			t.Errorf("expected error for missing file")
+		}
+	})
+}
+
+func TestRenderValue(t *testing.T) {
+	cases := []struct {
+		in  interface{}
+		exp string
+	}{
+		{int64(42), "42"},
+		{float64(1.5), "1.5"},
+		{[]interface{}{int64(1), int64(2)}, "1,2"},
+		{[]interface{}{[]interface{}{"a"}}, `[["a"]]`},
+		{map[string]interface{}{"b": "x:y", "a": "1"}, "a:1,b:x:y"},
+	}
+	for _, c := range cases {
+		val, err := renderValue(c.in)
+		if err != nil {
+			t.Errorf("unexpected error rendering %v: %s", c.in, err)
+		}
+		if val != c.exp {
+			t.Errorf("rendered %v as %s, expected %s", c.in, val, c.exp)
+		}
+	}
+
+	t.Run("elements containing a separator are an error", func(t *testing.T) {
+		for _, in := range []interface{}{
+			[]interface{}{"a,b", "c"},
+			map[string]interface{}{"a": "1,2"},
+			map[string]interface{}{"a:b": "1"},
+		} {
+			if val, err := renderValue(in); err == nil {
+				t.Errorf("expected error rendering %v, got %q", in, val)
+			}
+		}
+	})
+}
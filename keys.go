@@ -0,0 +1,76 @@
+package fig
+
+import (
+	"context"
+	"strings"
+)
+
+// KeyTransformDriver wraps another driver, mapping each key before it is read from the wrapped
+// driver. It lets drivers with different naming conventions, or a shared namespace, be used with
+// the same struct tags.
+type KeyTransformDriver struct {
+	driver    Driver
+	transform func(string) string
+}
+
+// WithKeyTransform wraps driver so that key is read as transform(key)
+func WithKeyTransform(driver Driver, transform func(string) string) KeyTransformDriver {
+	return KeyTransformDriver{driver: driver, transform: transform}
+}
+
+// WithPrefix wraps driver so that key is read as prefix+key, e.g. HOST as BILLING_HOST
+func WithPrefix(driver Driver, prefix string) KeyTransformDriver {
+	return WithKeyTransform(driver, func(key string) string {
+		return prefix + key
+	})
+}
+
+// Get returns the wrapped driver's value for the transformed key
+func (d KeyTransformDriver) Get(key string) (string, error) {
+	return d.driver.Get(d.transform(key))
+}
+
+// Name returns the wrapped driver's name
+func (d KeyTransformDriver) Name() string {
+	return d.driver.Name()
+}
+
+// Locate defers to the wrapped driver with the transformed key
+func (d KeyTransformDriver) Locate(key string) (string, int, bool) {
+	if locator, ok := d.driver.(Locator); ok {
+		return locator.Locate(d.transform(key))
+	}
+	return "", 0, false
+}
+
+// Watch defers to the wrapped driver if it implements Watcher, and otherwise blocks until ctx is
+// done
+func (d KeyTransformDriver) Watch(ctx context.Context, changed func()) error {
+	return watchDriver(ctx, d.driver, changed)
+}
+
+func (d KeyTransformDriver) watchable() bool {
+	return isWatchable(d.driver)
+}
+
+// KeyToPath maps an environment-style key to a dotted path, e.g. DB_HOST to db.host, for
+// reading structured files with env-style struct tags
+func KeyToPath(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "."))
+}
+
+// PathToKey maps a dotted path to an environment-style key, e.g. db.host to DB_HOST
+func PathToKey(path string) string {
+	return strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// Sub returns a Config reading keys under prefix from each of c's drivers, so that a package can
+// Unmarshal its own section without knowing where it lives. Parsers registered on c are kept.
+func Sub(c Config, prefix string) Config {
+	drivers := make([]Driver, len(c.drivers))
+	for i, driver := range c.drivers {
+		drivers[i] = WithPrefix(driver, prefix)
+	}
+	c.drivers = drivers
+	return c
+}
@@ -0,0 +1,85 @@
+package fig
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestKeyTransformDriver(t *testing.T) {
+	env := MapDriver{
+		"BILLING_DB_HOST": "billing.internal",
+		"DB_HOST":         "shared.internal",
+	}
+
+	t.Run("prefix", func(t *testing.T) {
+		driver := WithPrefix(env, "BILLING_")
+		val, err := driver.Get("DB_HOST")
+		if err != nil || val != "billing.internal" {
+			t.Errorf("expected billing.internal, got %q (%v)", val, err)
+		}
+		if _, err := driver.Get("BILLING_DB_HOST"); !errors.Is(err, ErrConfigNotFound) {
+			t.Errorf("expected ErrConfigNotFound, got %v", err)
+		}
+		if driver.Name() != "map" {
+			t.Errorf("expected wrapped driver name, got %s", driver.Name())
+		}
+	})
+
+	t.Run("transform", func(t *testing.T) {
+		driver := WithKeyTransform(env, PathToKey)
+		val, err := driver.Get("db.host")
+		if err != nil || val != "shared.internal" {
+			t.Errorf("expected shared.internal, got %q (%v)", val, err)
+		}
+	})
+
+	t.Run("locate uses transformed key", func(t *testing.T) {
+		path := writeTestFile(t, "config.json", `{"db": {"host": "localhost"}}`)
+		jsonDriver, err := NewJSONDriver(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		val, src, err := New(WithKeyTransform(jsonDriver, KeyToPath)).Lookup("DB_HOST")
+		if err != nil || val != "localhost" || src.File != path {
+			t.Errorf("unexpected lookup %q %+v (%v)", val, src, err)
+		}
+	})
+}
+
+func TestKeyMappings(t *testing.T) {
+	if got := KeyToPath("DB_HOST"); got != "db.host" {
+		t.Errorf("expected db.host, got %s", got)
+	}
+	if got := PathToKey("db.host"); got != "DB_HOST" {
+		t.Errorf("expected DB_HOST, got %s", got)
+	}
+}
+
+func TestSub(t *testing.T) {
+	type dbConfig struct {
+		Host string `fig:"DB_HOST" required:"true"`
+		Port port   `fig:"DB_PORT"`
+	}
+
+	conf := WithParser(New(MapDriver{
+		"BILLING_DB_HOST": "billing.internal",
+		"BILLING_DB_PORT": "5432",
+	}), func(s string) (port, error) {
+		return port(len(s)), nil
+	})
+
+	var cfg dbConfig
+	if err := Sub(conf, "BILLING_").Unmarshal(&cfg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cfg.Host != "billing.internal" || cfg.Port != 4 {
+		t.Errorf("unexpected values %+v", cfg)
+	}
+
+	if _, err := conf.GetString("DB_HOST"); !errors.Is(err, ErrConfigNotFound) {
+		t.Errorf("expected Sub to leave the original Config unscoped, got %v", err)
+	}
+}
+
+type port int
@@ -0,0 +1,334 @@
+package fig
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fieldOptions holds struct tag settings that affect how a value is parsed
+type fieldOptions struct {
+	sep    string
+	kvsep  string
+	layout string
+}
+
+// defaultFieldOptions are used for untagged values, such as those read by Get
+var defaultFieldOptions = fieldOptions{
+	sep:    ",",
+	kvsep:  ":",
+	layout: time.RFC3339,
+}
+
+// read parse settings from a struct field's tags
+func fieldOptionsFromTag(tag reflect.StructTag) fieldOptions {
+	opts := defaultFieldOptions
+	if sep, ok := tag.Lookup(sepTag); ok && sep != "" {
+		opts.sep = sep
+	}
+	if kvsep, ok := tag.Lookup(kvsepTag); ok && kvsep != "" {
+		opts.kvsep = kvsep
+	}
+	if layout, ok := tag.Lookup(layoutTag); ok && layout != "" {
+		opts.layout = layout
+	}
+	return opts
+}
+
+// parseFunc converts a raw config string into a value of a specific type
+type parseFunc func(value string, opts fieldOptions) (reflect.Value, error)
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = map[reflect.Type]parseFunc{}
+)
+
+// built-in parsers for standard library types, consulted after user-registered parsers
+var builtinParsers = map[reflect.Type]parseFunc{
+	reflect.TypeOf(time.Duration(0)): func(value string, _ fieldOptions) (reflect.Value, error) {
+		d, err := time.ParseDuration(value)
+		return reflect.ValueOf(d), err
+	},
+	reflect.TypeOf(time.Time{}): func(value string, opts fieldOptions) (reflect.Value, error) {
+		t, err := time.Parse(opts.layout, value)
+		return reflect.ValueOf(t), err
+	},
+	reflect.TypeOf(url.URL{}): func(value string, _ fieldOptions) (reflect.Value, error) {
+		u, err := url.Parse(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(*u), nil
+	},
+	reflect.TypeOf(net.IP{}): func(value string, _ fieldOptions) (reflect.Value, error) {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return reflect.Value{}, fmt.Errorf("invalid IP address %s", value)
+		}
+		return reflect.ValueOf(ip), nil
+	},
+	reflect.TypeOf(net.IPNet{}): func(value string, _ fieldOptions) (reflect.Value, error) {
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(*ipNet), nil
+	},
+	reflect.TypeOf(&regexp.Regexp{}): func(value string, _ fieldOptions) (reflect.Value, error) {
+		re, err := regexp.Compile(value)
+		return reflect.ValueOf(re), err
+	},
+	reflect.TypeOf(os.FileMode(0)): func(value string, _ fieldOptions) (reflect.Value, error) {
+		mode, err := strconv.ParseUint(value, 8, 32)
+		return reflect.ValueOf(os.FileMode(mode)), err
+	},
+}
+
+// Decoder may be implemented by config field types to parse themselves from a raw config value.
+// Decode is called on a pointer to a new zero value.
+type Decoder interface {
+	Decode(value string) error
+}
+
+var (
+	decoderType         = reflect.TypeOf((*Decoder)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// RegisterParser teaches fig how to parse values of type T. Registered parsers are used by
+// Unmarshal and by Get, MustGet and GetOr, and take precedence over fig's built-in parsing.
+// Registering a second parser for the same type replaces the first.
+// Use WithParser to register a parser for a single Config.
+func RegisterParser[T any](parse func(value string) (T, error)) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers[typeOf[T]()] = wrapParser(parse)
+}
+
+// WithParser returns a copy of c that parses values of type T with parse. Parsers added with
+// WithParser take precedence over those added with RegisterParser and fig's built-in parsing,
+// which makes them useful for types you don't own.
+func WithParser[T any](c Config, parse func(value string) (T, error)) Config {
+	scoped := make(map[reflect.Type]parseFunc, len(c.parsers)+1)
+	for t, p := range c.parsers {
+		scoped[t] = p
+	}
+	scoped[typeOf[T]()] = wrapParser(parse)
+	c.parsers = scoped
+	return c
+}
+
+// typeOf returns the reflect.Type of T, including interface types
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// adapt a typed parse function to a parseFunc
+func wrapParser[T any](parse func(value string) (T, error)) parseFunc {
+	return func(value string, _ fieldOptions) (reflect.Value, error) {
+		parsed, err := parse(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&parsed).Elem(), nil
+	}
+}
+
+// lookupParser returns the Config-scoped, registered or built-in parser for t, if any
+func (c Config) lookupParser(t reflect.Type) (parseFunc, bool) {
+	if parse, ok := c.parsers[t]; ok {
+		return parse, true
+	}
+
+	parsersMu.RLock()
+	parse, ok := parsers[t]
+	parsersMu.RUnlock()
+	if ok {
+		return parse, true
+	}
+
+	parse, ok = builtinParsers[t]
+	return parse, ok
+}
+
+// selfDecoding reports whether a pointer to t implements Decoder or encoding.TextUnmarshaler
+func selfDecoding(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	return ptr.Implements(decoderType) || ptr.Implements(textUnmarshalerType)
+}
+
+// decode value into a new t using its Decoder or encoding.TextUnmarshaler implementation
+func decodeSelf(t reflect.Type, value string) (reflect.Value, error) {
+	ptr := reflect.New(t)
+	var err error
+	switch target := ptr.Interface().(type) {
+	case Decoder:
+		err = target.Decode(value)
+	case encoding.TextUnmarshaler:
+		err = target.UnmarshalText([]byte(value))
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return ptr.Elem(), nil
+}
+
+// parseValue converts a raw config string into a value of type t. Parsers are tried in order:
+// Config-scoped, registered, built-in, then Decoder and encoding.TextUnmarshaler implementations,
+// and finally fig's handling of basic kinds. Pointer types are parsed as their element type and
+// returned as a pointer to the parsed value.
+// Slices and maps are split on opts.sep, and map entries on opts.kvsep.
+func (c Config) parseValue(t reflect.Type, key, value string, opts fieldOptions) (reflect.Value, error) {
+	if parse, ok := c.lookupParser(t); ok {
+		parsed, err := parse(value, opts)
+		if err != nil {
+			return reflect.Value{}, errConfigWrongType(key, value, t.String(), err)
+		}
+		return parsed, nil
+	}
+
+	if t.Kind() == reflect.Pointer {
+		elem, err := c.parseValue(t.Elem(), key, value, opts)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+
+	if selfDecoding(t) {
+		decoded, err := decodeSelf(t, value)
+		if err != nil {
+			return reflect.Value{}, errConfigWrongType(key, value, t.String(), err)
+		}
+		return decoded, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(value).Convert(t), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, integerBase(value), t.Bits())
+		if err != nil {
+			return reflect.Value{}, numericError(err, key, value, t)
+		}
+		return reflect.ValueOf(parsed).Convert(t), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, integerBase(value), t.Bits())
+		if err != nil {
+			return reflect.Value{}, numericError(err, key, value, t)
+		}
+		return reflect.ValueOf(parsed).Convert(t), nil
+
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return reflect.Value{}, errConfigWrongType(key, value, t.String(), err)
+		}
+		return reflect.ValueOf(parsed).Convert(t), nil
+
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, t.Bits())
+		if err != nil {
+			return reflect.Value{}, numericError(err, key, value, t)
+		}
+		return reflect.ValueOf(parsed).Convert(t), nil
+
+	case reflect.Slice:
+		return c.parseSlice(t, key, value, opts)
+
+	case reflect.Map:
+		return c.parseMap(t, key, value, opts)
+
+	default:
+		return reflect.Value{}, errConfigWrongType(key, value, t.String(), ErrUnsupportedType)
+	}
+}
+
+// parseSlice splits value on opts.sep and parses each element. Element errors use the key
+// KEY[i] so the offending element can be found. An empty value is an empty slice.
+func (c Config) parseSlice(t reflect.Type, key, value string, opts fieldOptions) (reflect.Value, error) {
+	if t.Elem().Kind() == reflect.Uint8 {
+		return reflect.ValueOf([]byte(value)).Convert(t), nil
+	}
+
+	if strings.TrimSpace(value) == "" {
+		return reflect.MakeSlice(t, 0, 0), nil
+	}
+
+	parts := strings.Split(value, opts.sep)
+	slice := reflect.MakeSlice(t, len(parts), len(parts))
+	for i, part := range parts {
+		elemKey := fmt.Sprintf("%s[%d]", key, i)
+		elem, err := c.parseValue(t.Elem(), elemKey, strings.TrimSpace(part), opts)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		slice.Index(i).Set(elem)
+	}
+
+	return slice, nil
+}
+
+// parseMap splits value into entries on opts.sep, and each entry into a key and value on
+// opts.kvsep. Entry errors use the key KEY[entry] so the offending entry can be found.
+func (c Config) parseMap(t reflect.Type, key, value string, opts fieldOptions) (reflect.Value, error) {
+	m := reflect.MakeMap(t)
+	if strings.TrimSpace(value) == "" {
+		return m, nil
+	}
+
+	for i, entry := range strings.Split(value, opts.sep) {
+		k, v, ok := strings.Cut(entry, opts.kvsep)
+		if !ok {
+			return reflect.Value{}, errConfigWrongType(key, value, t.String(), fmt.Errorf("entry %d ('%s') is missing key/value separator '%s'", i, entry, opts.kvsep))
+		}
+		k = strings.TrimSpace(k)
+
+		entryKey := fmt.Sprintf("%s[%s]", key, k)
+		parsedKey, err := c.parseValue(t.Key(), entryKey, k, opts)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		parsedVal, err := c.parseValue(t.Elem(), entryKey, strings.TrimSpace(v), opts)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		m.SetMapIndex(parsedKey, parsedVal)
+	}
+
+	return m, nil
+}
+
+// integerBase returns 0, letting strconv infer the base, for values with a 0x, 0o or 0b prefix,
+// and 10 otherwise so that values with leading zeros are not read as octal
+func integerBase(value string) int {
+	digits := strings.TrimLeft(value, "+-")
+	if len(digits) > 2 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X', 'o', 'O', 'b', 'B':
+			return 0
+		}
+	}
+	return 10
+}
+
+// build the error for a failed numeric parse, distinguishing out of range values
+func numericError(err error, key, value string, t reflect.Type) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return errConfigOutOfRange(key, value, t.String())
+	}
+	return errConfigWrongType(key, value, t.String(), err)
+}
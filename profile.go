@@ -0,0 +1,101 @@
+package fig
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ProfileVariable is the environment variable NewProfileDriver reads the profile from when none
+// is given
+const ProfileVariable = "FIG_PROFILE"
+
+// ProfileDriver is an EnvironmentDriver reading a base .env file and overlays for a profile such
+// as "dev" or "prod"
+type ProfileDriver struct {
+	EnvironmentDriver
+	profile string
+}
+
+// ProfileOption configures a ProfileDriver
+type ProfileOption func(*profileOptions)
+
+type profileOptions struct {
+	dir string
+}
+
+// ProfileDir reads the .env files from dir rather than the working directory
+func ProfileDir(dir string) ProfileOption {
+	return func(o *profileOptions) {
+		o.dir = dir
+	}
+}
+
+// ProfileFiles returns the .env files read for profile, in ascending precedence, following the
+// convention used by Next.js and Rails:
+//
+//	.env
+//	.env.<profile>
+//	.env.local
+//	.env.<profile>.local
+//
+// .local files are for machine-specific overrides and shouldn't be committed. As with Next.js,
+// .env.local is skipped for the "test" profile so tests behave the same on every machine. With
+// no profile only .env and .env.local are read.
+func ProfileFiles(profile string) []string {
+	files := []string{".env"}
+	if profile != "" {
+		files = append(files, ".env."+profile)
+	}
+	if profile != "test" {
+		files = append(files, ".env.local")
+	}
+	if profile != "" {
+		files = append(files, ".env."+profile+".local")
+	}
+	return files
+}
+
+// NewProfileDriver reads the ProfileFiles for profile that are present, with later files taking
+// precedence over earlier ones and the real environment taking precedence over all of them. If
+// profile is empty it is read from FIG_PROFILE. Files that are missing are skipped, and are read
+// if they appear while the driver is watched.
+func NewProfileDriver(profile string, opts ...ProfileOption) (ProfileDriver, error) {
+	options := profileOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if profile == "" {
+		profile = os.Getenv(ProfileVariable)
+	}
+
+	files := ProfileFiles(profile)
+	for i, file := range files {
+		files[i] = filepath.Join(options.dir, file)
+	}
+
+	driver, err := NewEnvDriver(Files(files...), OptionalFiles())
+	if err != nil {
+		return ProfileDriver{}, err
+	}
+	return ProfileDriver{EnvironmentDriver: driver, profile: profile}, nil
+}
+
+// NewWithProfile initializes a config reading a ProfileDriver for profile, followed by drivers
+func NewWithProfile(profile string, drivers ...Driver) (Config, error) {
+	driver, err := NewProfileDriver(profile)
+	if err != nil {
+		return Config{}, err
+	}
+	return New(append([]Driver{driver}, drivers...)...), nil
+}
+
+// Profile returns the driver's profile, which may be empty
+func (d ProfileDriver) Profile() string {
+	return d.profile
+}
+
+// Files returns the .env files that were present and read, in ascending precedence
+func (d ProfileDriver) Files() []string {
+	return append([]string(nil), d.files.get().present...)
+}
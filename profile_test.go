@@ -0,0 +1,130 @@
+package fig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfileFiles(t *testing.T) {
+	cases := []struct {
+		profile  string
+		expected []string
+	}{
+		{"", []string{".env", ".env.local"}},
+		{"prod", []string{".env", ".env.prod", ".env.local", ".env.prod.local"}},
+		{"test", []string{".env", ".env.test", ".env.test.local"}},
+	}
+	for _, tc := range cases {
+		if files := ProfileFiles(tc.profile); !reflect.DeepEqual(files, tc.expected) {
+			t.Errorf("profile %q: expected %v, got %v", tc.profile, tc.expected, files)
+		}
+	}
+}
+
+// write .env files into a directory
+func writeProfileFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestProfileDriver(t *testing.T) {
+	dir := writeProfileFiles(t, map[string]string{
+		".env":            "LEVEL=base\nBASE=base\nPROD=base\nLOCAL=base\n",
+		".env.prod":       "LEVEL=prod\nPROD=prod\n",
+		".env.local":      "LEVEL=local\nLOCAL=local\n",
+		".env.prod.local": "LEVEL=prod.local\n",
+		".env.test":       "LEVEL=test\n",
+	})
+
+	t.Run("overlays in ascending precedence", func(t *testing.T) {
+		driver, err := NewProfileDriver("prod", ProfileDir(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		conf := New(driver)
+		for key, expected := range map[string]string{"LEVEL": "prod.local", "BASE": "base", "PROD": "prod", "LOCAL": "local"} {
+			if val := conf.MustGetString(key); val != expected {
+				t.Errorf("expected %s=%s, got %s", key, expected, val)
+			}
+		}
+
+		_, src, err := conf.Lookup("PROD")
+		if err != nil || src.File != filepath.Join(dir, ".env.prod") {
+			t.Errorf("unexpected source %+v (%v)", src, err)
+		}
+	})
+
+	t.Run("reports present files", func(t *testing.T) {
+		driver, err := NewProfileDriver("test", ProfileDir(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{filepath.Join(dir, ".env"), filepath.Join(dir, ".env.test")}
+		if files := driver.Files(); !reflect.DeepEqual(files, expected) {
+			t.Errorf("expected %v, got %v", expected, files)
+		}
+		if val := New(driver).MustGetString("LOCAL"); val != "base" {
+			t.Errorf("expected .env.local to be skipped for test profile, got LOCAL=%s", val)
+		}
+	})
+
+	t.Run("profile from FIG_PROFILE", func(t *testing.T) {
+		t.Setenv(ProfileVariable, "prod")
+		driver, err := NewProfileDriver("", ProfileDir(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if driver.Profile() != "prod" {
+			t.Errorf("expected prod profile, got %q", driver.Profile())
+		}
+	})
+
+	t.Run("no files present", func(t *testing.T) {
+		driver, err := NewProfileDriver("prod", ProfileDir(t.TempDir()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(driver.Files()) != 0 {
+			t.Errorf("expected no files, got %v", driver.Files())
+		}
+		if _, err := New(driver).GetString("LEVEL"); err == nil {
+			t.Errorf("expected LEVEL to be missing")
+		}
+	})
+}
+
+func TestNewWithProfile(t *testing.T) {
+	dir := writeProfileFiles(t, map[string]string{
+		".env":         "LEVEL=base\n",
+		".env.staging": "LEVEL=staging\n",
+	})
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+
+	conf, err := NewWithProfile("staging", MapDriver{"EXTRA": "yes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val := conf.MustGetString("LEVEL"); val != "staging" {
+		t.Errorf("expected staging, got %s", val)
+	}
+	if val := conf.MustGetString("EXTRA"); val != "yes" {
+		t.Errorf("expected fallback driver to be read, got %s", val)
+	}
+}
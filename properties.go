@@ -0,0 +1,360 @@
+package fig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// flatFile holds the keys and values read from a line-oriented config file
+type flatFile struct {
+	path   string
+	values map[string]string
+	lines  map[string]int
+}
+
+// Get returns the value for key, or ErrConfigNotFound
+func (f flatFile) Get(key string) (string, error) {
+	val, ok := f.values[key]
+	if !ok {
+		return "", ErrConfigNotFound
+	}
+	return val, nil
+}
+
+// Locate returns the file and line a key was read from
+func (f flatFile) Locate(key string) (string, int, bool) {
+	line, ok := f.lines[key]
+	if !ok {
+		return "", 0, false
+	}
+	return f.path, line, true
+}
+
+// set records a key's value and line. Later definitions replace earlier ones.
+func (f *flatFile) set(key, value string, line int) {
+	f.values[key] = value
+	f.lines[key] = line
+}
+
+// flatFileDriver reads keys from a flatFile, reloading it when the file changes
+type flatFileDriver struct {
+	file *watchedFiles[flatFile]
+}
+
+// loadFlatFile reads the file at path and parses it with parse
+func loadFlatFile(path string, parse func(path, data string) (flatFile, error)) (flatFileDriver, error) {
+	file, err := loadWatchedFiles([]string{path}, func() (flatFile, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return flatFile{}, err
+		}
+		return parse(path, string(data))
+	})
+	if err != nil {
+		return flatFileDriver{}, err
+	}
+	return flatFileDriver{file: file}, nil
+}
+
+func (d flatFileDriver) Get(key string) (string, error) {
+	return d.file.get().Get(key)
+}
+
+func (d flatFileDriver) Locate(key string) (string, int, bool) {
+	return d.file.get().Locate(key)
+}
+
+// Watch polls the file, reloading it when it changes
+func (d flatFileDriver) Watch(ctx context.Context, changed func()) error {
+	return d.file.watch(ctx, changed)
+}
+
+// PropertiesDriver supports reading from Java .properties files. Keys and values may be
+// separated by '=', ':' or whitespace, lines starting with '#' or '!' are comments, lines ending
+// in a backslash continue on the next line, and escapes such as \t and \u00e9 are decoded.
+type PropertiesDriver struct {
+	flatFileDriver
+}
+
+// NewPropertiesDriver reads and parses the .properties file at path. A malformed escape sequence
+// is an error.
+func NewPropertiesDriver(path string) (PropertiesDriver, error) {
+	driver, err := loadFlatFile(path, parseProperties)
+	if err != nil {
+		return PropertiesDriver{}, err
+	}
+	return PropertiesDriver{flatFileDriver: driver}, nil
+}
+
+func (d PropertiesDriver) Name() string {
+	return "properties"
+}
+
+// parse the contents of a .properties file
+func parseProperties(path, data string) (flatFile, error) {
+	file := flatFile{path: path, values: map[string]string{}, lines: map[string]int{}}
+	for _, l := range logicalLines(data, continuesProperty, func(trimmed string) bool {
+		return strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "!")
+	}) {
+		rawKey, rawValue := splitProperty(l.text)
+		key, err := unescapeProperty(rawKey)
+		if err != nil {
+			return flatFile{}, fmt.Errorf("%s:%d: %w", path, l.line, err)
+		}
+		value, err := unescapeProperty(rawValue)
+		if err != nil {
+			return flatFile{}, fmt.Errorf("%s:%d: %w", path, l.line, err)
+		}
+		file.set(key, value, l.line)
+	}
+	return file, nil
+}
+
+// splitProperty splits a logical line into its still-escaped key and value. The key ends at the
+// first unescaped '=', ':' or whitespace, which may be surrounded by whitespace.
+func splitProperty(text string) (string, string) {
+	end := len(text)
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", text[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	key := text[:end]
+	rest := strings.TrimLeft(text[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return key, rest
+}
+
+// unescapeProperty decodes backslash escapes. \t, \n, \r and \f are control characters,
+// \uXXXX is a unicode code point, and a backslash before any other character yields that
+// character.
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			r, n, err := parseUnicodeEscape(s[i+1:])
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+			i += n
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// parse the four hex digits following \u, returning the rune and the number of bytes consumed. A
+// high surrogate followed by a \uXXXX low surrogate, as in \ud83d\ude00, is combined into one rune.
+func parseUnicodeEscape(s string) (rune, int, error) {
+	r, err := parseHex4(s)
+	if err != nil {
+		return 0, 0, err
+	}
+	if utf16.IsSurrogate(r) && strings.HasPrefix(s[4:], `\u`) {
+		if low, err := parseHex4(s[6:]); err == nil {
+			if combined := utf16.DecodeRune(r, low); combined != unicode.ReplacementChar {
+				return combined, 10, nil
+			}
+		}
+	}
+	return r, 4, nil
+}
+
+// parse the four hex digits of a \uXXXX escape
+func parseHex4(s string) (rune, error) {
+	if len(s) < 4 {
+		return 0, fmt.Errorf("malformed \\uxxxx escape: \\u%s", s)
+	}
+	code, err := strconv.ParseUint(s[:4], 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("malformed \\uxxxx escape: \\u%s", s[:4])
+	}
+	return rune(code), nil
+}
+
+// INIDriver supports reading from .ini files. Keys inside a [section] are qualified with the
+// section name, e.g. "database.user", while keys before the first section are unqualified.
+// Keys and values are separated by '=' or ':', lines starting with ';' or '#' are comments, as
+// is the rest of a value after a ';' or '#' that follows whitespace, values may be wrapped in
+// matching quotes, lines ending in whitespace and a backslash continue on the next line, and
+// \uXXXX escapes are decoded. Other backslashes are kept, so Windows paths such as C:\users\ are
+// read as written.
+type INIDriver struct {
+	flatFileDriver
+}
+
+// NewINIDriver reads and parses the .ini file at path. Malformed lines are an error.
+func NewINIDriver(path string) (INIDriver, error) {
+	driver, err := loadFlatFile(path, parseINI)
+	if err != nil {
+		return INIDriver{}, err
+	}
+	return INIDriver{flatFileDriver: driver}, nil
+}
+
+func (d INIDriver) Name() string {
+	return "ini"
+}
+
+// parse the contents of a .ini file
+func parseINI(path, data string) (flatFile, error) {
+	file := flatFile{path: path, values: map[string]string{}, lines: map[string]int{}}
+	section := ""
+	for _, l := range logicalLines(data, continuesINI, func(trimmed string) bool {
+		return strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#")
+	}) {
+		if header := strings.TrimSpace(l.text); strings.HasPrefix(header, "[") {
+			if !strings.HasSuffix(header, "]") {
+				return flatFile{}, fmt.Errorf("%s:%d: unterminated section header %s", path, l.line, header)
+			}
+			section = strings.TrimSpace(header[1 : len(header)-1])
+			continue
+		}
+
+		end := strings.IndexAny(l.text, "=:")
+		if end <= 0 {
+			return flatFile{}, fmt.Errorf("%s:%d: expected key = value, got %s", path, l.line, l.text)
+		}
+
+		key := strings.TrimSpace(l.text[:end])
+		if section != "" {
+			key = section + "." + key
+		}
+		value := unescapeINI(unquote(stripINIComment(strings.TrimSpace(l.text[end+1:]))))
+		file.set(key, value, l.line)
+	}
+	return file, nil
+}
+
+// stripINIComment removes an inline comment, started by ';' or '#' after whitespace, from a
+// trimmed value. Comment characters inside a quoted value, or starting it, are kept.
+func stripINIComment(value string) string {
+	var quote byte
+	if value != "" && (value[0] == '"' || value[0] == '\'') {
+		quote = value[0]
+	}
+
+	inQuote := false
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case quote != 0 && c == quote:
+			inQuote = !inQuote
+		case !inQuote && (c == ';' || c == '#') && i > 0 && (value[i-1] == ' ' || value[i-1] == '\t'):
+			return strings.TrimRight(value[:i], " \t")
+		}
+	}
+	return value
+}
+
+// unquote removes one pair of matching surrounding quotes
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// unescapeINI decodes \uXXXX escapes, leaving other backslashes, including a \u not followed by
+// four hex digits as in C:\users, in place
+func unescapeINI(s string) string {
+	if !strings.Contains(s, `\u`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && s[i+1] == 'u' {
+			if r, n, err := parseUnicodeEscape(s[i+2:]); err == nil {
+				b.WriteRune(r)
+				i += n + 1
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// logicalLine is a line of a config file after joining continuations
+type logicalLine struct {
+	text string
+	// line is the 1-based line number the logical line starts on
+	line int
+}
+
+// logicalLines splits data into non-blank, non-comment lines with leading whitespace removed. A
+// line for which continues reports true continues on the next line, with its final backslash and
+// the next line's leading whitespace removed. Comment lines are never continued.
+func logicalLines(data string, continues func(text string) bool, isComment func(trimmed string) bool) []logicalLine {
+	data = strings.TrimPrefix(data, "\ufeff")
+	physical := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+
+	var lines []logicalLine
+	for i := 0; i < len(physical); i++ {
+		start := i + 1
+		text := strings.TrimLeft(physical[i], " \t\f")
+		if text == "" || isComment(text) {
+			continue
+		}
+
+		for continues(text) && i+1 < len(physical) {
+			i++
+			text = text[:len(text)-1] + strings.TrimLeft(physical[i], " \t\f")
+		}
+		if continues(text) {
+			text = text[:len(text)-1]
+		}
+
+		lines = append(lines, logicalLine{text: text, line: start})
+	}
+	return lines
+}
+
+// continuesINI reports whether an INI line ends in whitespace and a backslash, so that a value
+// ending in a Windows path separator, like C:\temp\, isn't continued
+func continuesINI(text string) bool {
+	return strings.HasSuffix(text, " \\") || strings.HasSuffix(text, "\t\\")
+}
+
+// continuesProperty reports whether a .properties line ends in an odd number of backslashes
+func continuesProperty(text string) bool {
+	n := 0
+	for i := len(text) - 1; i >= 0 && text[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
@@ -0,0 +1,166 @@
+package fig
+
+import (
+	"errors"
+	"testing"
+)
+
+const testProperties = `# database settings
+! also a comment
+db.host = db.internal
+db.port:5432
+db.user admin
+greeting = caf\u00e9
+smile = \ud83d\ude00
+multi = first, \
+        second, \
+        third
+path = C:\\data\\app
+key\ with\ spaces = spaced
+tabbed = a\tb
+db.host = db.override
+`
+
+func TestPropertiesDriver(t *testing.T) {
+	path := writeTestFile(t, "app.properties", testProperties)
+	driver, err := NewPropertiesDriver(path)
+	if err != nil {
+		t.Fatalf("unexpected error creating properties driver: %s", err)
+	}
+
+	cases := map[string]string{
+		"db.host":         "db.override",
+		"db.port":         "5432",
+		"db.user":         "admin",
+		"greeting":        "café",
+		"smile":           "😀",
+		"multi":           "first, second, third",
+		"path":            `C:\data\app`,
+		"key with spaces": "spaced",
+		"tabbed":          "a\tb",
+	}
+	for key, exp := range cases {
+		val, err := driver.Get(key)
+		if err != nil {
+			t.Errorf("unexpected error for key %s: %s", key, err)
+		}
+		if val != exp {
+			t.Errorf("unexpected value %q for key %s, expected %q", val, key, exp)
+		}
+	}
+
+	t.Run("comments and missing keys return ErrConfigNotFound", func(t *testing.T) {
+		for _, key := range []string{"# database settings", "!", "db.password"} {
+			if _, err := driver.Get(key); !errors.Is(err, ErrConfigNotFound) {
+				t.Errorf("expected ErrConfigNotFound for key %s, got %v", key, err)
+			}
+		}
+	})
+
+	t.Run("Locate reports the line of the last definition", func(t *testing.T) {
+		file, line, ok := driver.Locate("db.host")
+		if !ok || file != path || line != 14 {
+			t.Errorf("unexpected location %s:%d", file, line)
+		}
+		_, line, _ = driver.Locate("multi")
+		if line != 8 {
+			t.Errorf("continued lines should be located at their first line, got %d", line)
+		}
+	})
+
+	t.Run("malformed escapes fail construction", func(t *testing.T) {
+		if _, err := NewPropertiesDriver(writeTestFile(t, "bad.properties", `bad = \u00zz`)); err == nil {
+			t.Errorf("expected error for malformed unicode escape")
+		}
+	})
+}
+
+const testINI = `; global settings
+name = fig
+
+[database]
+user = admin
+password = "p@ss;word"
+host: db.internal
+port = 5432 ; default postgres port
+color = #336699
+quoted = "a ; b" # trailing comment
+
+# server settings
+[server]
+path = C:\srv\app
+home = C:\users\bob
+temp = C:\temp\
+after = temp
+motd = hello \
+       world
+emoji = \u263A
+smile = \ud83d\ude00
+`
+
+func TestINIDriver(t *testing.T) {
+	driver, err := NewINIDriver(writeTestFile(t, "app.ini", testINI))
+	if err != nil {
+		t.Fatalf("unexpected error creating INI driver: %s", err)
+	}
+
+	cases := map[string]string{
+		"name":              "fig",
+		"database.user":     "admin",
+		"database.password": "p@ss;word",
+		"database.host":     "db.internal",
+		"database.port":     "5432",
+		"database.color":    "#336699",
+		"database.quoted":   "a ; b",
+		"server.path":       `C:\srv\app`,
+		"server.home":       `C:\users\bob`,
+		"server.temp":       `C:\temp\`,
+		"server.after":      "temp",
+		"server.motd":       "hello world",
+		"server.emoji":      "☺",
+		"server.smile":      "😀",
+	}
+	for key, exp := range cases {
+		val, err := driver.Get(key)
+		if err != nil {
+			t.Errorf("unexpected error for key %s: %s", key, err)
+		}
+		if val != exp {
+			t.Errorf("unexpected value %q for key %s, expected %q", val, key, exp)
+		}
+	}
+
+	t.Run("unqualified section keys return ErrConfigNotFound", func(t *testing.T) {
+		for _, key := range []string{"user", "database", "server.user"} {
+			if _, err := driver.Get(key); !errors.Is(err, ErrConfigNotFound) {
+				t.Errorf("expected ErrConfigNotFound for key %s, got %v", key, err)
+			}
+		}
+	})
+
+	t.Run("Unmarshal reads sections with prefixes", func(t *testing.T) {
+		var ts struct {
+			DB struct {
+				User string `fig:"user" required:"true"`
+				Host string `fig:"host" required:"true"`
+			} `prefix:"database."`
+		}
+		if err := New(driver).Unmarshal(&ts); err != nil {
+			t.Fatalf("unexpected error from Unmarshal: %s", err)
+		}
+		if ts.DB.User != "admin" || ts.DB.Host != "db.internal" {
+			t.Errorf("unexpected values %+v", ts)
+		}
+	})
+
+	t.Run("malformed lines fail construction", func(t *testing.T) {
+		for name, contents := range map[string]string{
+			"header.ini": "[database\nuser = admin",
+			"key.ini":    "[database]\njust a line",
+		} {
+			if _, err := NewINIDriver(writeTestFile(t, name, contents)); err == nil {
+				t.Errorf("expected error parsing %s", name)
+			}
+		}
+	})
+}
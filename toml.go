@@ -0,0 +1,63 @@
+package fig
+
+import (
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// TOMLDriver supports reading from a TOML file. Keys are table paths, such as
+// "server.http.port", and arrays of tables are indexed like "servers[0].host".
+//
+// Offset datetimes are returned in RFC 3339 format, which time.Time fields parse by default.
+// Local dates, times and datetimes are returned as written, e.g. "1979-05-27", and need a
+// matching `layout` tag.
+type TOMLDriver struct {
+	treeFileDriver
+}
+
+// NewTOMLDriver reads and parses the TOML file at path. A malformed file is an error.
+func NewTOMLDriver(path string) (TOMLDriver, error) {
+	driver, err := loadTreeFile(path, "TOML", parseTOML)
+	if err != nil {
+		return TOMLDriver{}, err
+	}
+	return TOMLDriver{treeFileDriver: driver}, nil
+}
+
+// parse a TOML document
+func parseTOML(data []byte) (interface{}, error) {
+	var root map[string]interface{}
+	if err := toml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	return normalizeTOML(root), nil
+}
+
+// normalizeTOML converts the date and time values of a decoded TOML document to strings. Offset
+// datetimes are formatted as RFC 3339 and local dates and times are kept as written.
+func normalizeTOML(node interface{}) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for k, v := range n {
+			n[k] = normalizeTOML(v)
+		}
+	case []interface{}:
+		for i, v := range n {
+			n[i] = normalizeTOML(v)
+		}
+	case time.Time:
+		return n.Format(time.RFC3339Nano)
+	case toml.LocalDate:
+		return n.String()
+	case toml.LocalTime:
+		return n.String()
+	case toml.LocalDateTime:
+		return n.String()
+	}
+	return node
+}
+
+func (d TOMLDriver) Name() string {
+	return "toml"
+}
@@ -0,0 +1,97 @@
+package fig
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const testTOML = `
+title = "fig"
+started = 2023-04-05T06:07:08Z
+launch = 1979-05-27
+alarm = 07:32:00
+liftoff = 1979-05-27T07:32:00
+
+[server.http]
+port = 8080
+ratio = 0.5
+origins = ["a.com", "b.com"]
+
+[[servers]]
+host = "a.internal"
+port = 9000
+
+[[servers]]
+host = "b.internal"
+port = 9001
+`
+
+func TestTOMLDriver(t *testing.T) {
+	driver, err := NewTOMLDriver(writeTestFile(t, "config.toml", testTOML))
+	if err != nil {
+		t.Fatalf("unexpected error creating TOML driver: %s", err)
+	}
+
+	t.Run("table paths resolve to rendered values", func(t *testing.T) {
+		cases := map[string]string{
+			"title":               "fig",
+			"server.http.port":    "8080",
+			"server.http.ratio":   "0.5",
+			"server.http.origins": "a.com,b.com",
+			"servers[1].host":     "b.internal",
+			"servers.0.port":      "9000",
+			"started":             "2023-04-05T06:07:08Z",
+			"launch":              "1979-05-27",
+			"alarm":               "07:32:00",
+			"liftoff":             "1979-05-27T07:32:00",
+		}
+		for key, exp := range cases {
+			val, err := driver.Get(key)
+			if err != nil {
+				t.Errorf("unexpected error for key %s: %s", key, err)
+			}
+			if val != exp {
+				t.Errorf("unexpected value %s for key %s, expected %s", val, key, exp)
+			}
+		}
+	})
+
+	t.Run("missing keys return ErrConfigNotFound", func(t *testing.T) {
+		for _, key := range []string{"server.https.port", "servers[2].host", "owner"} {
+			if _, err := driver.Get(key); !errors.Is(err, ErrConfigNotFound) {
+				t.Errorf("expected ErrConfigNotFound for key %s, got %v", key, err)
+			}
+		}
+	})
+
+	t.Run("datetimes unmarshal into time.Time", func(t *testing.T) {
+		var ts struct {
+			Started time.Time `fig:"started"`
+			Launch  time.Time `fig:"launch" layout:"2006-01-02"`
+			Port    uint16    `fig:"server.http.port"`
+		}
+		if err := New(driver).Unmarshal(&ts); err != nil {
+			t.Fatalf("unexpected error from Unmarshal: %s", err)
+		}
+		if !ts.Started.Equal(time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)) {
+			t.Errorf("unexpected offset datetime %s", ts.Started)
+		}
+		if !ts.Launch.Equal(time.Date(1979, 5, 27, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("unexpected local date %s", ts.Launch)
+		}
+		if ts.Port != 8080 {
+			t.Errorf("unexpected port %d", ts.Port)
+		}
+	})
+
+	t.Run("malformed files fail construction", func(t *testing.T) {
+		_, err := NewTOMLDriver(writeTestFile(t, "bad.toml", "[server\nport = "))
+		if err == nil {
+			t.Errorf("expected error for malformed TOML")
+		}
+		if errors.Is(err, ErrConfigNotFound) {
+			t.Errorf("parse errors should be distinct from ErrConfigNotFound")
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package fig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// treeFileDriver reads keys as paths into a document decoded from a structured config file, such
+// as "db.host" or "servers[0].port", reloading it when the file changes
+type treeFileDriver struct {
+	path string
+	root *watchedFiles[interface{}]
+}
+
+// loadTreeFile reads the file at path and decodes it with parse. format names the kind of file
+// in errors, e.g. "JSON".
+func loadTreeFile(path, format string, parse func(data []byte) (interface{}, error)) (treeFileDriver, error) {
+	root, err := loadWatchedFiles([]string{path}, func() (interface{}, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		root, err := parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s file %s: %w", format, path, err)
+		}
+		return root, nil
+	})
+	if err != nil {
+		return treeFileDriver{}, err
+	}
+	return treeFileDriver{path: path, root: root}, nil
+}
+
+// Get returns the value at the key's path. Scalars are returned as written, lists of scalars are
+// comma separated, maps of scalars are rendered as key:value entries and other values as JSON.
+// Missing paths and null values return ErrConfigNotFound.
+func (d treeFileDriver) Get(key string) (string, error) {
+	val, ok := lookupPath(d.root.get(), key)
+	if !ok {
+		return "", ErrConfigNotFound
+	}
+
+	return renderValue(val)
+}
+
+// Locate returns the file for keys it contains. Line numbers are not tracked.
+func (d treeFileDriver) Locate(key string) (string, int, bool) {
+	if _, ok := lookupPath(d.root.get(), key); !ok {
+		return "", 0, false
+	}
+	return d.path, 0, true
+}
+
+// Watch polls the file, reloading it when it changes
+func (d treeFileDriver) Watch(ctx context.Context, changed func()) error {
+	return d.root.watch(ctx, changed)
+}
+
+// lookupPath resolves a key such as "db.host" or "servers[0].port" in a document decoded from a
+// structured config file. At each level a map key matching the whole remaining path is preferred,
+// so keys that themselves contain dots can still be read. Slice elements may be addressed as
+// "servers[0]" or "servers.0". Null values are treated as missing.
+func lookupPath(node interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return node, node != nil
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if child, ok := n[path]; ok {
+			return child, child != nil
+		}
+		segment, rest := splitPath(path)
+		child, ok := n[segment]
+		if !ok {
+			return nil, false
+		}
+		return lookupPath(child, rest)
+
+	case []interface{}:
+		segment, rest := splitPath(strings.TrimPrefix(path, "["))
+		i, err := strconv.Atoi(strings.TrimSuffix(segment, "]"))
+		if err != nil || i < 0 || i >= len(n) {
+			return nil, false
+		}
+		return lookupPath(n[i], rest)
+	}
+
+	return nil, false
+}
+
+// splitPath splits the first segment from a path, returning the segment and the remaining path
+// without its leading dot. Index segments keep their closing bracket, e.g. "0]".
+func splitPath(path string) (string, string) {
+	end := strings.IndexAny(path, ".[")
+	if end == -1 {
+		return path, ""
+	}
+	if end == 0 {
+		// path starts with an index after a previous index, e.g. "[1]" in "[0][1]"
+		return splitPath(path[1:])
+	}
+	if path[end] == '.' {
+		return path[:end], path[end+1:]
+	}
+	return path[:end], path[end:]
+}
+
+// renderValue formats a value from a structured config file as a string fig's parsers accept.
+// Lists of scalars are comma separated and maps of scalars are rendered as comma separated
+// key:value entries, matching the default `sep` and `kvsep`. Elements containing a separator
+// can't be split apart again, so they are an error; read them individually by index or key
+// instead. Other values are rendered as JSON.
+func renderValue(v interface{}) (string, error) {
+	if s, ok := renderScalar(v); ok {
+		return s, nil
+	}
+
+	if list, ok, err := scalarList(v); ok {
+		return strings.Join(list, ","), err
+	}
+
+	if entries, ok, err := scalarMap(v); ok {
+		return strings.Join(entries, ","), err
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to render value: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// render a scalar value, reporting false for lists, maps and other composite values
+func renderScalar(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case json.Number:
+		return s.String(), true
+	case bool:
+		return strconv.FormatBool(s), true
+	case int:
+		return strconv.Itoa(s), true
+	case int64:
+		return strconv.FormatInt(s, 10), true
+	case uint64:
+		return strconv.FormatUint(s, 10), true
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// render a list whose elements are all scalars. Elements containing a comma are an error.
+func scalarList(v interface{}) ([]string, bool, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, false, nil
+	}
+
+	list := make([]string, rv.Len())
+	for i := range list {
+		s, ok := renderScalar(rv.Index(i).Interface())
+		if !ok {
+			return nil, false, nil
+		}
+		if strings.Contains(s, ",") {
+			return nil, true, fmt.Errorf("list element %q contains the separator ','; read it as [%d]", s, i)
+		}
+		list[i] = s
+	}
+	return list, true, nil
+}
+
+// render a map whose keys and values are all scalars as sorted key:value entries. Keys containing
+// a comma or colon and values containing a comma are an error.
+func scalarMap(v interface{}) ([]string, bool, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map {
+		return nil, false, nil
+	}
+
+	entries := make([]string, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		k, ok := renderScalar(iter.Key().Interface())
+		if !ok {
+			return nil, false, nil
+		}
+		val, ok := renderScalar(iter.Value().Interface())
+		if !ok {
+			return nil, false, nil
+		}
+		if strings.ContainsAny(k, ",:") {
+			return nil, true, fmt.Errorf("map key %q contains the separator ',' or ':'", k)
+		}
+		if strings.Contains(val, ",") {
+			return nil, true, fmt.Errorf("map value %q for key %s contains the separator ','; read it as .%s", val, k, k)
+		}
+		entries = append(entries, k+":"+val)
+	}
+	sort.Strings(entries)
+	return entries, true, nil
+}
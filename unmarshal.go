@@ -2,9 +2,7 @@ package fig
 
 import (
 	"errors"
-	"fmt"
 	"reflect"
-	"strconv"
 	"strings"
 )
 
@@ -12,6 +10,16 @@ const (
 	configTag   = "fig"
 	requiredTag = "required"
 	defaultTag  = "default"
+	prefixTag   = "prefix"
+	sepTag      = "sep"
+	kvsepTag    = "kvsep"
+	layoutTag   = "layout"
+	secretTag   = "secret"
+	usageTag    = "usage"
+	validateTag = "validate"
+
+	// reported as the Driver of values and errors from `default` tags
+	defaultDriverName = "default"
 )
 
 // Unmarshal configuration from driver(s) into a struct. `dest“ should be a pointer to a struct
@@ -20,150 +28,330 @@ const (
 // Drivers will be used in configured order
 // The `default` tag can specify a default value that will be used if no configured driver has the key
 // `required="true"` has no effect for fields with a valid default value
-func (c Config) Unmarshal(dest interface{}) error {
+//
+// Nested and embedded structs are populated recursively. The `prefix` tag, or a `fig` tag on the
+// struct field, is prepended to the keys of every field inside it, so with `fig:"DB_"` on a DB
+// field, `DB_HOST` fills DB.Host. Pointers to structs are only followed when tagged, and are left
+// nil unless a driver sets at least one of their fields. Defaults and required fields inside them
+// only apply once they are allocated.
+//
+// Slice fields are split on commas and map fields into comma separated key:value entries. The
+// `sep` and `kvsep` tags override those separators.
+//
+// time.Duration, time.Time, url.URL, net.IP, net.IPNet, *regexp.Regexp and os.FileMode are
+// supported natively. time.Time fields are parsed as RFC 3339 unless a `layout` tag is given.
+// Other types can be supported with RegisterParser or WithParser, or by implementing Decoder or
+// encoding.TextUnmarshaler.
+//
+// Unmarshal populates every field it can before returning. If any field fails, the returned
+// error is an UnmarshalErrors listing each failed field. Errors for fields tagged
+// `secret:"true"` don't include their values.
+//
+// The `validate` tag checks values after they are set, e.g. `validate:"min=1,max=65535"`. The
+// rules are min, max and len, which compare numbers, durations and the length of strings, slices
+// and maps; oneof, taking | separated values; regex, whose pattern runs to the end of the tag;
+// nonzero; url, for absolute URLs; and hostport. RegisterValidator adds rules. Only fields that
+// are set are validated, so combine rules with `required` to insist on a value. Failures are
+// reported as a ValidationError, and the failing value is still assigned to the field. Unknown
+// rules are reported for every field using them, set or not, and leave the field untouched.
+//
+// Options such as WithSources customize a single call.
+func (c Config) Unmarshal(dest interface{}, opts ...UnmarshalOption) error {
+	under, err := structValue(dest)
+	if err != nil {
+		return err
+	}
+
+	state := &unmarshalState{}
+	for _, opt := range opts {
+		opt(state)
+	}
+
+	c.walkStruct(under, "", "", func(f configField) bool {
+		return c.unmarshalField(f, state)
+	})
+	state.recordDefaultSources()
+	if errs := state.failures(); len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// structValue returns the struct dest points to
+func structValue(dest interface{}) (reflect.Value, error) {
 	refVal := reflect.ValueOf(dest)
 	if refVal.Kind() != reflect.Pointer {
-		return errors.New("destination in Unmarshal must be a pointer to a struct")
+		return reflect.Value{}, errors.New("destination in Unmarshal must be a pointer to a struct")
 	}
 
 	if refVal.Elem().Kind() != reflect.Struct {
-		return errors.New("destination pointer must be to a struct")
+		return reflect.Value{}, errors.New("destination pointer must be to a struct")
 	}
 
-	refType := reflect.TypeOf(dest)
+	return refVal.Elem(), nil
+}
+
+// UnmarshalOption customizes a single call to Unmarshal
+type UnmarshalOption func(*unmarshalState)
+
+// WithSources fills sources with the Source of every config key Unmarshal sets, keyed by the
+// full config key. Keys set from a `default` tag have the Source Driver "default".
+func WithSources(sources map[string]Source) UnmarshalOption {
+	return func(s *unmarshalState) {
+		s.sources = sources
+	}
+}
+
+// settings and results of a single Unmarshal call
+type unmarshalState struct {
+	errs    UnmarshalErrors
+	sources map[string]Source
+	// optional holds the sections of errors from defaults and required fields inside nil pointer
+	// sections, which are dropped if the section is left nil
+	optional map[*FieldError]*optionalSection
+	// defaults holds the sections of keys set from defaults inside nil pointer sections
+	defaults map[string]*optionalSection
+}
+
+// addSectionError records an error from a default or required field of f, which only counts if
+// f's optional section, if any, is allocated
+func (s *unmarshalState) addSectionError(f configField, fieldErr *FieldError) {
+	s.errs = append(s.errs, fieldErr)
+	if f.section != nil {
+		if s.optional == nil {
+			s.optional = map[*FieldError]*optionalSection{}
+		}
+		s.optional[fieldErr] = f.section
+	}
+}
+
+// recordDefault records that f was set from its default, once its section is known to be set
+func (s *unmarshalState) recordDefault(f configField) {
+	if f.section == nil {
+		s.recordSource(f.key, Source{Driver: defaultDriverName})
+		return
+	}
+	if s.defaults == nil {
+		s.defaults = map[string]*optionalSection{}
+	}
+	s.defaults[f.key] = f.section
+}
+
+// recordDefaultSources records the sources of defaults inside optional sections that were set
+func (s *unmarshalState) recordDefaultSources() {
+	for key, section := range s.defaults {
+		if section.set {
+			s.recordSource(key, Source{Driver: defaultDriverName})
+		}
+	}
+}
+
+// failures returns the errors of the call, leaving out errors from defaults and missing required
+// fields of optional sections that were left nil
+func (s *unmarshalState) failures() UnmarshalErrors {
+	var errs UnmarshalErrors
+	for _, err := range s.errs {
+		if section, ok := s.optional[err]; ok && !section.set {
+			continue
+		}
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// record where the value for key came from, if requested
+func (s *unmarshalState) recordSource(key string, src Source) {
+	if s.sources != nil {
+		s.sources[key] = src
+	}
+}
+
+// configField is a tagged field found while walking a config struct
+type configField struct {
+	value reflect.Value
+	field reflect.StructField
+	// key is the full config key, including prefixes
+	key string
+	// path is the full path to the field, e.g. DB.Host
+	path string
+	// section is the innermost nil pointer to a struct containing the field, if any
+	section *optionalSection
+}
+
+// optionalSection is a nil pointer to a struct being walked. It is only allocated if a driver
+// sets one of its fields, so its defaults and required fields only apply in that case.
+type optionalSection struct {
+	set bool
+}
+
+// walkStruct calls visit for each tagged field of under that fig parses from a single value,
+// prepending keyPrefix to each config key and pathPrefix to each field path. Nested structs
+// are walked recursively. visit reports whether a driver set the field, which decides whether nil
+// pointers to structs are allocated. Reports whether any field was set.
+func (c Config) walkStruct(under reflect.Value, keyPrefix, pathPrefix string, visit func(configField) bool) bool {
+	return c.walkSection(under, keyPrefix, pathPrefix, nil, visit)
+}
+
+// walkSection walks the fields of under, which is part of section if it isn't nil
+func (c Config) walkSection(under reflect.Value, keyPrefix, pathPrefix string, section *optionalSection, visit func(configField) bool) bool {
+	refType := under.Type()
+	anySet := false
 
-	under := refVal.Elem()
 	for i := 0; i < under.NumField(); i++ {
 		field := under.Field(i)
-		fieldType := refType.Elem().Field(i)
-		fieldHasBeenSet := false
+		fieldType := refType.Field(i)
+		fieldName := pathPrefix + fieldType.Name
 
-		// skip unexported fields
-		if !fieldType.IsExported() {
+		// skip unexported fields, except embedded structs whose exported fields are promoted
+		if !fieldType.IsExported() && !(fieldType.Anonymous && fieldType.Type.Kind() == reflect.Struct) {
 			continue
 		}
 
 		// check if this field expects to get a value from fig
-		configKey, ok := fieldType.Tag.Lookup(configTag)
-		if !ok {
-			continue
-		}
+		configKey, hasKey := fieldType.Tag.Lookup(configTag)
 
-		// try each driver in configured order
-		for _, driver := range c.drivers {
-			configVal, err := driver.Get(configKey)
-			if err != nil {
-				// if this driver simply doesn't know this key, try the next one
-				if errors.Is(err, ErrConfigNotFound) {
-					continue
-				}
-				return fmt.Errorf("error reading key %s from driver %s: %w", configKey, driver.Name(), err)
+		// recurse into structs fig doesn't know how to parse as a single value
+		if c.isNestedStruct(fieldType.Type) {
+			nestedPrefix, hasPrefix := fieldType.Tag.Lookup(prefixTag)
+			if !hasPrefix {
+				nestedPrefix = configKey
 			}
-
-			if err = c.setFieldValue(field, fieldType.Type, configKey, configVal); err != nil {
-				return fmt.Errorf("failed to unmarshal config key %s (value %s) into field %s: %w", configKey, configVal, fieldType.Name, err)
+			if fieldType.Type.Kind() == reflect.Pointer && !hasPrefix && !hasKey {
+				continue
 			}
 
-			fieldHasBeenSet = true
-			break
+			set := c.walkNested(field, keyPrefix+nestedPrefix, fieldName+".", section, visit)
+			anySet = anySet || set
+			continue
 		}
 
-		// if the field wasn't set, check for a default value, then make sure it wasn't a required field
-		if !fieldHasBeenSet {
-			defaultVal, ok := fieldType.Tag.Lookup(defaultTag)
-			if ok {
-				err := c.setFieldValue(field, fieldType.Type, configKey, defaultVal)
-				if err != nil {
-					return fmt.Errorf("failed to unmarshal default value %s for key %s into field %s: %w", defaultVal, configKey, fieldType.Name, err)
-				}
-				fieldHasBeenSet = true
-			} else {
-				if requiredVal, ok := fieldType.Tag.Lookup(requiredTag); ok && strings.ToLower(requiredVal) == "true" {
-					return fmt.Errorf("required field %s (config key %s) not found in any configured driver", fieldType.Name, configKey)
-				}
-			}
+		if !hasKey || !fieldType.IsExported() {
+			continue
 		}
+
+		set := visit(configField{value: field, field: fieldType, key: keyPrefix + configKey, path: fieldName, section: section})
+		anySet = anySet || set
 	}
 
-	return nil
+	return anySet
 }
 
-// supports same primitive types supported by the Config `getFoo` methods
-// int, int64, bool, string, float64
-func (c Config) setFieldValue(field reflect.Value, fieldType reflect.Type, key, value string) error {
-	ft := fieldType
-	isPtr := false
-	if fieldType.Kind() == reflect.Pointer {
-		isPtr = true
-		ft = fieldType.Elem()
+// walk a nested struct or pointer to struct. Nil pointers are only allocated if a driver sets a
+// field, and start a new optional section. Defaults are written to the new struct as it is
+// walked, and discarded with it if nothing is set.
+func (c Config) walkNested(field reflect.Value, keyPrefix, pathPrefix string, section *optionalSection, visit func(configField) bool) bool {
+	if field.Kind() != reflect.Pointer {
+		return c.walkSection(field, keyPrefix, pathPrefix, section, visit)
+	}
+	if !field.IsNil() {
+		return c.walkSection(field.Elem(), keyPrefix, pathPrefix, section, visit)
+	}
+
+	target := reflect.New(field.Type().Elem())
+	section = &optionalSection{}
+	section.set = c.walkSection(target.Elem(), keyPrefix, pathPrefix, section, visit)
+	if section.set {
+		field.Set(target)
 	}
+	return section.set
+}
 
-	var refVal reflect.Value
+// populate a single field from the drivers or its default, recording failures in state.
+// Reports whether a driver set the field, since defaults alone don't allocate optional sections.
+func (c Config) unmarshalField(f configField, state *unmarshalState) bool {
+	if err := checkValidationRules(f); err != nil {
+		state.errs = append(state.errs, &FieldError{Field: f.path, Key: f.key, Err: err})
+		return false
+	}
 
-	switch ft.Kind() {
-	case reflect.String:
-		if isPtr {
-			refVal = reflect.ValueOf(&value)
-		} else {
-			refVal = reflect.ValueOf(value)
+	// read from the drivers in configured order
+	configVal, src, err := c.lookup(f.key)
+	var driverErr *DriverError
+	switch {
+	case err == nil:
+		if err = c.setFieldValue(f.value, f.field, f.key, configVal); err != nil {
+			state.errs = append(state.errs, &FieldError{Field: f.path, Key: f.key, Driver: src.Driver, Err: err})
+			return false
 		}
-		field.Set(refVal)
-		return nil
-
-	case reflect.Int:
-		if parsed, err := strconv.Atoi(value); err != nil {
-			return errConfigWrongType(key, value, typeInt)
-		} else {
-			if isPtr {
-				refVal = reflect.ValueOf(&parsed)
-			} else {
-				refVal = reflect.ValueOf(parsed)
-			}
-			field.Set(refVal)
-			return nil
+		state.recordSource(f.key, src)
+		if err := validateField(f, configVal); err != nil {
+			state.errs = append(state.errs, &FieldError{Field: f.path, Key: f.key, Driver: src.Driver, Err: err})
 		}
+		return true
+	case errors.As(err, &driverErr):
+		state.errs = append(state.errs, &FieldError{Field: f.path, Key: f.key, Driver: driverErr.Driver, Err: err})
+		return false
+	case !errors.Is(err, ErrConfigNotFound):
+		state.errs = append(state.errs, &FieldError{Field: f.path, Key: f.key, Driver: src.Driver, Err: err})
+		return false
+	}
 
-	case reflect.Int64:
-		if parsed, err := strconv.ParseInt(value, 10, 64); err != nil {
-			return errConfigWrongType(key, value, typeInt64)
-		} else {
-			if isPtr {
-				refVal = reflect.ValueOf(&parsed)
-			} else {
-				refVal = reflect.ValueOf(parsed)
-			}
-			field.Set(refVal)
-			return nil
+	// if the field wasn't set, check for a default value, then make sure it wasn't a required field
+	if defaultVal, ok, err := c.defaultValue(f); ok {
+		if err != nil {
+			state.addSectionError(f, &FieldError{Field: f.path, Key: f.key, Driver: defaultDriverName, Err: err})
+			return false
 		}
-
-	case reflect.Bool:
-		if parsed, err := strconv.ParseBool(value); err != nil {
-			return errConfigWrongType(key, value, typeBool)
-		} else {
-			if isPtr {
-				refVal = reflect.ValueOf(&parsed)
-			} else {
-				refVal = reflect.ValueOf(parsed)
-			}
-			field.Set(refVal)
-			return nil
+		if err := c.setFieldValue(f.value, f.field, f.key, defaultVal); err != nil {
+			state.addSectionError(f, &FieldError{Field: f.path, Key: f.key, Driver: defaultDriverName, Err: err})
+			return false
 		}
+		state.recordDefault(f)
+		if err := validateField(f, defaultVal); err != nil {
+			state.addSectionError(f, &FieldError{Field: f.path, Key: f.key, Driver: defaultDriverName, Err: err})
+		}
+		return false
+	}
 
-	case reflect.Float64:
-		if parsed, err := strconv.ParseFloat(value, 64); err != nil {
-			return errConfigWrongType(key, value, typeFloat64)
-		} else {
-			if isPtr {
-				refVal = reflect.ValueOf(&parsed)
-			} else {
-				refVal = reflect.ValueOf(parsed)
-			}
-			field.Set(refVal)
-			return nil
+	if isRequired(f.field) {
+		state.addSectionError(f, &FieldError{Field: f.path, Key: f.key, Err: ErrRequiredNotFound})
+	}
+	return false
+}
+
+// defaultValue returns the field's `default` tag, if it has one, expanding references to other
+// keys when interpolation is enabled
+func (c Config) defaultValue(f configField) (string, bool, error) {
+	val, ok := f.field.Tag.Lookup(defaultTag)
+	if !ok || !c.interpolate {
+		return val, ok, nil
+	}
+	val, err := c.expand(f.key, val, []string{f.key})
+	return val, true, err
+}
+
+// isRequired reports whether a field is tagged `required:"true"`
+func isRequired(field reflect.StructField) bool {
+	requiredVal, ok := field.Tag.Lookup(requiredTag)
+	return ok && strings.ToLower(requiredVal) == "true"
+}
+
+// isNestedStruct reports whether t is a struct or pointer to struct that should be populated
+// field by field rather than parsed from a single value
+func (c Config) isNestedStruct(t reflect.Type) bool {
+	if _, ok := c.lookupParser(t); ok {
+		return false
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		if _, ok := c.lookupParser(t); ok {
+			return false
 		}
+	}
+	return t.Kind() == reflect.Struct && !selfDecoding(t)
+}
 
-	default:
-		return fmt.Errorf("fig does not support config fields of type %s: supported values are (*)string, (*)int, (*)int64, (*)float64 and (*)bool", fieldType.Kind().String())
+// parses value into the field's type and sets it
+func (c Config) setFieldValue(field reflect.Value, fieldType reflect.StructField, key, value string) error {
+	parsed, err := c.parseValue(fieldType.Type, key, value, fieldOptionsFromTag(fieldType.Tag))
+	if err != nil {
+		if isSecret(fieldType) {
+			return maskParseError(err)
+		}
+		return err
 	}
 
+	field.Set(parsed)
+	return nil
 }
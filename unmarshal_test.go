@@ -1,7 +1,16 @@
 package fig
 
 import (
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 type optionalTestStruct struct {
@@ -246,3 +255,638 @@ func TestUnmarshal(t *testing.T) {
 		}
 	})
 }
+
+type nestedDBConfig struct {
+	Host string `fig:"HOST" required:"true"`
+	Port int    `fig:"PORT" default:"5432"`
+}
+
+type nestedEmbedded struct {
+	Name string `fig:"NAME"`
+}
+
+type nestedTestStruct struct {
+	nestedEmbedded
+	DB      nestedDBConfig  `fig:"DB_"`
+	Replica *nestedDBConfig `prefix:"REPLICA_"`
+	Debug   bool            `fig:"DEBUG"`
+}
+
+type exportedEmbedded struct {
+	Region string `fig:"REGION"`
+}
+
+type embeddedTestStruct struct {
+	exportedEmbedded `prefix:"APP_"`
+}
+
+func TestUnmarshalNested(t *testing.T) {
+	driver := testDriver{
+		vals: map[string]string{
+			"DB_HOST":      "db.internal",
+			"REPLICA_HOST": "replica.internal",
+			"REPLICA_PORT": "5433",
+			"DEBUG":        "true",
+			"NAME":         "svc",
+			"APP_REGION":   "us-east-1",
+		},
+	}
+	conf := New(driver)
+
+	var ts nestedTestStruct
+	if err := conf.Unmarshal(&ts); err != nil {
+		t.Fatalf("unexpected error from Unmarshal: %s", err)
+	}
+
+	t.Run("prefixed struct fields are populated", func(t *testing.T) {
+		if ts.DB.Host != "db.internal" {
+			t.Errorf("nested string has incorrect value %s", ts.DB.Host)
+		}
+		if ts.DB.Port != 5432 {
+			t.Errorf("nested default has incorrect value %d", ts.DB.Port)
+		}
+	})
+
+	t.Run("pointer to struct is allocated when populated", func(t *testing.T) {
+		if ts.Replica == nil {
+			t.Fatalf("expected pointer to struct to be allocated")
+		}
+		if ts.Replica.Host != "replica.internal" || ts.Replica.Port != 5433 {
+			t.Errorf("nested pointer struct has incorrect value %+v", *ts.Replica)
+		}
+	})
+
+	t.Run("pointer to struct stays nil when nothing is set", func(t *testing.T) {
+		var partial struct {
+			Cache *nestedEmbedded `prefix:"CACHE_"`
+		}
+		if err := conf.Unmarshal(&partial); err != nil {
+			t.Errorf("unexpected error from Unmarshal: %s", err)
+		}
+		if partial.Cache != nil {
+			t.Errorf("expected unset pointer to struct to remain nil")
+		}
+	})
+
+	t.Run("top level fields are still populated", func(t *testing.T) {
+		if !ts.Debug {
+			t.Errorf("top level bool has incorrect value %t", ts.Debug)
+		}
+	})
+
+	t.Run("embedded structs are populated", func(t *testing.T) {
+		if ts.Name != "svc" {
+			t.Errorf("embedded string has incorrect value %s", ts.Name)
+		}
+
+		var es embeddedTestStruct
+		if err := conf.Unmarshal(&es); err != nil {
+			t.Errorf("unexpected error from Unmarshal: %s", err)
+		}
+		if es.Region != "us-east-1" {
+			t.Errorf("prefixed embedded string has incorrect value %s", es.Region)
+		}
+	})
+
+	t.Run("required fields of an absent optional section are ignored", func(t *testing.T) {
+		type credentials struct {
+			User     string `fig:"USER" required:"true"`
+			Password string `fig:"PASSWORD" required:"true"`
+		}
+		var optional struct {
+			Auth  *credentials `prefix:"AUTH_"`
+			Debug bool         `fig:"DEBUG"`
+		}
+		if err := conf.Unmarshal(&optional); err != nil {
+			t.Errorf("unexpected error from Unmarshal: %s", err)
+		}
+		if optional.Auth != nil {
+			t.Errorf("expected absent section to remain nil")
+		}
+
+		partial := New(testDriver{vals: map[string]string{"AUTH_USER": "admin"}})
+		err := partial.Unmarshal(&optional)
+		var fieldErrs UnmarshalErrors
+		if !errors.As(err, &fieldErrs) || len(fieldErrs) != 1 || fieldErrs[0].Key != "AUTH_PASSWORD" {
+			t.Errorf("expected error for AUTH_PASSWORD once the section is present, got %v", err)
+		}
+	})
+
+	t.Run("defaults do not allocate an absent optional section", func(t *testing.T) {
+		type redis struct {
+			Host string `fig:"HOST" required:"true"`
+			Port int    `fig:"PORT" default:"6379"`
+		}
+		var optional struct {
+			Redis *redis `fig:"REDIS_"`
+		}
+		sources := map[string]Source{}
+		if err := conf.Unmarshal(&optional, WithSources(sources)); err != nil {
+			t.Errorf("unexpected error from Unmarshal: %s", err)
+		}
+		if optional.Redis != nil {
+			t.Errorf("expected section with only defaults to remain nil, got %+v", optional.Redis)
+		}
+		if _, ok := sources["REDIS_PORT"]; ok {
+			t.Errorf("expected no source for the default of an absent section")
+		}
+
+		present := New(testDriver{vals: map[string]string{"REDIS_HOST": "cache.internal"}})
+		if err := present.Unmarshal(&optional, WithSources(sources)); err != nil {
+			t.Fatalf("unexpected error from Unmarshal: %s", err)
+		}
+		if optional.Redis == nil || optional.Redis.Host != "cache.internal" || optional.Redis.Port != 6379 {
+			t.Errorf("expected defaults once the section is present, got %+v", optional.Redis)
+		}
+		if sources["REDIS_PORT"].Driver != defaultDriverName {
+			t.Errorf("expected default source for REDIS_PORT, got %+v", sources["REDIS_PORT"])
+		}
+	})
+
+	t.Run("required nested fields error when missing", func(t *testing.T) {
+		var missing struct {
+			DB nestedDBConfig `prefix:"MISSING_"`
+		}
+		err := conf.Unmarshal(&missing)
+		if err == nil {
+			t.Fatalf("missing required nested field should error on unmarshal")
+		}
+		if !strings.Contains(err.Error(), "MISSING_HOST") || !strings.Contains(err.Error(), "DB.Host") {
+			t.Errorf("error should name the prefixed key and nested field: %s", err)
+		}
+	})
+}
+
+type collectionTestStruct struct {
+	Origins   []string          `fig:"ORIGINS"`
+	Ports     []int             `fig:"PORTS" sep:";"`
+	Labels    map[string]string `fig:"LABELS"`
+	Weights   map[string]int    `fig:"WEIGHTS" sep:"|" kvsep:"="`
+	Empty     []string          `fig:"EMPTY"`
+	Defaulted []int64           `fig:"DEFAULTED" default:"1,2,3"`
+}
+
+func TestUnmarshalCollections(t *testing.T) {
+	driver := testDriver{
+		vals: map[string]string{
+			"ORIGINS":  "a.com, b.com,c.com",
+			"PORTS":    "80;443",
+			"LABELS":   "env:prod,team:core",
+			"WEIGHTS":  "a=1|b=2",
+			"EMPTY":    "",
+			"BAD_INT":  "1,two,3",
+			"BAD_MAP":  "env:prod,team",
+			"BAD_MVAL": "a:1,b:x",
+		},
+	}
+	conf := New(driver)
+
+	var ts collectionTestStruct
+	if err := conf.Unmarshal(&ts); err != nil {
+		t.Fatalf("unexpected error from Unmarshal: %s", err)
+	}
+
+	t.Run("slices are split and trimmed", func(t *testing.T) {
+		if !reflect.DeepEqual(ts.Origins, []string{"a.com", "b.com", "c.com"}) {
+			t.Errorf("[]string has incorrect value %v", ts.Origins)
+		}
+		if !reflect.DeepEqual(ts.Ports, []int{80, 443}) {
+			t.Errorf("[]int with custom separator has incorrect value %v", ts.Ports)
+		}
+		if ts.Empty == nil || len(ts.Empty) != 0 {
+			t.Errorf("empty value should produce empty slice, got %v", ts.Empty)
+		}
+		if !reflect.DeepEqual(ts.Defaulted, []int64{1, 2, 3}) {
+			t.Errorf("[]int64 default has incorrect value %v", ts.Defaulted)
+		}
+	})
+
+	t.Run("maps are split into entries", func(t *testing.T) {
+		if !reflect.DeepEqual(ts.Labels, map[string]string{"env": "prod", "team": "core"}) {
+			t.Errorf("map[string]string has incorrect value %v", ts.Labels)
+		}
+		if !reflect.DeepEqual(ts.Weights, map[string]int{"a": 1, "b": 2}) {
+			t.Errorf("map[string]int with custom separators has incorrect value %v", ts.Weights)
+		}
+	})
+
+	t.Run("element errors name the index", func(t *testing.T) {
+		var bad struct {
+			Ints []int `fig:"BAD_INT"`
+		}
+		err := conf.Unmarshal(&bad)
+		if err == nil || !strings.Contains(err.Error(), "BAD_INT[1]") {
+			t.Errorf("expected error naming element index, got %v", err)
+		}
+	})
+
+	t.Run("entry errors name the key", func(t *testing.T) {
+		var bad struct {
+			Ints map[string]int `fig:"BAD_MVAL"`
+		}
+		err := conf.Unmarshal(&bad)
+		if err == nil || !strings.Contains(err.Error(), "BAD_MVAL[b]") {
+			t.Errorf("expected error naming map key, got %v", err)
+		}
+
+		var missingSep struct {
+			Labels map[string]string `fig:"BAD_MAP"`
+		}
+		if err := conf.Unmarshal(&missingSep); err == nil {
+			t.Errorf("expected error for entry without key/value separator")
+		}
+	})
+
+	t.Run("Get supports slices with default separator", func(t *testing.T) {
+		origins, err := Get[[]string](conf, "ORIGINS")
+		if err != nil {
+			t.Errorf("unexpected error from Get: %s", err)
+		}
+		if len(origins) != 3 {
+			t.Errorf("unexpected value %v from Get", origins)
+		}
+	})
+}
+
+type stdlibTestStruct struct {
+	Timeout  time.Duration   `fig:"TIMEOUT"`
+	Started  time.Time       `fig:"STARTED"`
+	Birthday time.Time       `fig:"BIRTHDAY" layout:"2006-01-02"`
+	Endpoint *url.URL        `fig:"ENDPOINT"`
+	Bind     net.IP          `fig:"BIND"`
+	Subnet   net.IPNet       `fig:"SUBNET"`
+	Pattern  *regexp.Regexp  `fig:"PATTERN"`
+	Mode     os.FileMode     `fig:"MODE"`
+	Backoff  []time.Duration `fig:"BACKOFF"`
+}
+
+func TestUnmarshalStdlibTypes(t *testing.T) {
+	driver := testDriver{
+		vals: map[string]string{
+			"TIMEOUT":  "1m30s",
+			"STARTED":  "2023-04-05T06:07:08Z",
+			"BIRTHDAY": "1990-12-31",
+			"ENDPOINT": "https://example.com/api?x=1",
+			"BIND":     "127.0.0.1",
+			"SUBNET":   "10.0.0.0/8",
+			"PATTERN":  "^[a-z]+$",
+			"MODE":     "0640",
+			"BACKOFF":  "1s,2s,4s",
+		},
+	}
+	conf := New(driver)
+
+	var ts stdlibTestStruct
+	if err := conf.Unmarshal(&ts); err != nil {
+		t.Fatalf("unexpected error from Unmarshal: %s", err)
+	}
+
+	if ts.Timeout != 90*time.Second {
+		t.Errorf("time.Duration has incorrect value %s", ts.Timeout)
+	}
+	if !ts.Started.Equal(time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)) {
+		t.Errorf("time.Time has incorrect value %s", ts.Started)
+	}
+	if !ts.Birthday.Equal(time.Date(1990, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("time.Time with layout has incorrect value %s", ts.Birthday)
+	}
+	if ts.Endpoint == nil || ts.Endpoint.Host != "example.com" || ts.Endpoint.Query().Get("x") != "1" {
+		t.Errorf("*url.URL has incorrect value %v", ts.Endpoint)
+	}
+	if !ts.Bind.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("net.IP has incorrect value %s", ts.Bind)
+	}
+	if !ts.Subnet.Contains(net.IPv4(10, 1, 2, 3)) {
+		t.Errorf("net.IPNet has incorrect value %s", ts.Subnet.String())
+	}
+	if ts.Pattern == nil || !ts.Pattern.MatchString("abc") || ts.Pattern.MatchString("ABC") {
+		t.Errorf("*regexp.Regexp has incorrect value %v", ts.Pattern)
+	}
+	if ts.Mode != 0640 {
+		t.Errorf("os.FileMode has incorrect value %o", ts.Mode)
+	}
+	if !reflect.DeepEqual(ts.Backoff, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}) {
+		t.Errorf("[]time.Duration has incorrect value %v", ts.Backoff)
+	}
+
+	t.Run("malformed values error", func(t *testing.T) {
+		bad := New(testDriver{vals: map[string]string{
+			"TIMEOUT": "soon",
+			"BIND":    "localhost",
+			"PATTERN": "[",
+			"MODE":    "rwx",
+		}})
+
+		var d struct {
+			Timeout time.Duration `fig:"TIMEOUT"`
+		}
+		var ip struct {
+			Bind net.IP `fig:"BIND"`
+		}
+		var re struct {
+			Pattern *regexp.Regexp `fig:"PATTERN"`
+		}
+		var mode struct {
+			Mode os.FileMode `fig:"MODE"`
+		}
+		for _, dest := range []interface{}{&d, &ip, &re, &mode} {
+			if err := bad.Unmarshal(dest); err == nil {
+				t.Errorf("expected error unmarshaling malformed value into %T", dest)
+			}
+		}
+	})
+}
+
+type textLevel int
+
+func (l *textLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 0
+	case "warn":
+		*l = 2
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+type decodedSize int64
+
+func (s *decodedSize) Decode(value string) error {
+	n, err := strconv.ParseInt(strings.TrimSuffix(value, "KB"), 10, 64)
+	if err != nil {
+		return err
+	}
+	*s = decodedSize(n * 1024)
+	return nil
+}
+
+type decodedPair struct {
+	Left, Right string
+}
+
+func (p *decodedPair) Decode(value string) error {
+	left, right, ok := strings.Cut(value, "/")
+	if !ok {
+		return errors.New("pair must contain /")
+	}
+	p.Left, p.Right = left, right
+	return nil
+}
+
+type foreignType struct {
+	value string
+}
+
+type decoderTestStruct struct {
+	Level    textLevel    `fig:"LEVEL"`
+	LevelPtr *textLevel   `fig:"LEVEL"`
+	Size     decodedSize  `fig:"SIZE"`
+	Pair     decodedPair  `fig:"PAIR"`
+	Foreign  foreignType  `fig:"FOREIGN"`
+	Levels   []textLevel  `fig:"LEVELS"`
+	Sizes    *decodedSize `fig:"MISSING"`
+}
+
+func TestUnmarshalDecoders(t *testing.T) {
+	driver := testDriver{
+		vals: map[string]string{
+			"LEVEL":   "warn",
+			"SIZE":    "4KB",
+			"PAIR":    "a/b",
+			"FOREIGN": "theirs",
+			"LEVELS":  "debug,warn",
+		},
+	}
+	conf := WithParser(New(driver), func(value string) (foreignType, error) {
+		return foreignType{value: strings.ToUpper(value)}, nil
+	})
+
+	var ts decoderTestStruct
+	if err := conf.Unmarshal(&ts); err != nil {
+		t.Fatalf("unexpected error from Unmarshal: %s", err)
+	}
+
+	if ts.Level != 2 || ts.LevelPtr == nil || *ts.LevelPtr != 2 {
+		t.Errorf("encoding.TextUnmarshaler field has incorrect value %d", ts.Level)
+	}
+	if ts.Size != 4096 {
+		t.Errorf("Decoder field has incorrect value %d", ts.Size)
+	}
+	if ts.Pair.Left != "a" || ts.Pair.Right != "b" {
+		t.Errorf("Decoder struct field has incorrect value %+v", ts.Pair)
+	}
+	if ts.Foreign.value != "THEIRS" {
+		t.Errorf("WithParser field has incorrect value %+v", ts.Foreign)
+	}
+	if !reflect.DeepEqual(ts.Levels, []textLevel{0, 2}) {
+		t.Errorf("slice of encoding.TextUnmarshaler has incorrect value %v", ts.Levels)
+	}
+	if ts.Sizes != nil {
+		t.Errorf("unset Decoder pointer should be nil")
+	}
+
+	t.Run("WithParser does not affect other configs", func(t *testing.T) {
+		var foreign struct {
+			Foreign foreignType `fig:"FOREIGN"`
+		}
+		if err := New(driver).Unmarshal(&foreign); err != nil {
+			t.Errorf("unexpected error from Unmarshal: %s", err)
+		}
+		if foreign.Foreign.value != "" {
+			t.Errorf("parser registered with WithParser leaked to another Config")
+		}
+	})
+
+	t.Run("decode errors are reported", func(t *testing.T) {
+		bad := New(testDriver{vals: map[string]string{"LEVEL": "loud"}})
+		var level struct {
+			Level textLevel `fig:"LEVEL"`
+		}
+		if err := bad.Unmarshal(&level); err == nil {
+			t.Errorf("expected error from failing UnmarshalText")
+		}
+	})
+}
+
+type numericTestStruct struct {
+	Int8    int8    `fig:"INT8"`
+	Int16   int16   `fig:"INT16"`
+	Int32   int32   `fig:"INT32"`
+	Uint    uint    `fig:"UINT"`
+	Uint8   uint8   `fig:"UINT8"`
+	Port    uint16  `fig:"PORT"`
+	Uint32  uint32  `fig:"UINT32"`
+	Uint64  uint64  `fig:"UINT64"`
+	Ratio   float32 `fig:"RATIO"`
+	Padded  int     `fig:"PADDED"`
+	Octal   int     `fig:"OCTAL"`
+	Binary  *uint8  `fig:"BINARY"`
+	Negated int64   `fig:"NEGATED"`
+}
+
+func TestUnmarshalNumeric(t *testing.T) {
+	driver := testDriver{
+		vals: map[string]string{
+			"INT8":    "-128",
+			"INT16":   "0x7fff",
+			"INT32":   "2147483647",
+			"UINT":    "42",
+			"UINT8":   "255",
+			"PORT":    "8080",
+			"UINT32":  "0xFFFFFFFF",
+			"UINT64":  "18446744073709551615",
+			"RATIO":   "0.25",
+			"PADDED":  "010",
+			"OCTAL":   "0o10",
+			"BINARY":  "0b101",
+			"NEGATED": "-0x10",
+		},
+	}
+
+	var ts numericTestStruct
+	if err := New(driver).Unmarshal(&ts); err != nil {
+		t.Fatalf("unexpected error from Unmarshal: %s", err)
+	}
+
+	exp := numericTestStruct{
+		Int8: -128, Int16: 0x7fff, Int32: 2147483647, Uint: 42, Uint8: 255, Port: 8080,
+		Uint32: 0xFFFFFFFF, Uint64: 18446744073709551615, Ratio: 0.25, Padded: 10, Octal: 8, Negated: -16,
+	}
+	if ts.Binary == nil || *ts.Binary != 5 {
+		t.Errorf("*uint8 with binary prefix has incorrect value %v", ts.Binary)
+	}
+	ts.Binary = nil
+	if ts != exp {
+		t.Errorf("numeric fields have incorrect values\n got: %+v\nwant: %+v", ts, exp)
+	}
+
+	t.Run("out of range values return RangeError", func(t *testing.T) {
+		bad := New(testDriver{vals: map[string]string{
+			"PORT":  "65536",
+			"INT8":  "-129",
+			"RATIO": "1e39",
+		}})
+
+		var port struct {
+			Port uint16 `fig:"PORT"`
+		}
+		var small struct {
+			Int8 int8 `fig:"INT8"`
+		}
+		var ratio struct {
+			Ratio float32 `fig:"RATIO"`
+		}
+		for _, dest := range []interface{}{&port, &small, &ratio} {
+			err := bad.Unmarshal(dest)
+			var rangeErr *RangeError
+			if !errors.As(err, &rangeErr) {
+				t.Errorf("expected RangeError unmarshaling into %T, got %v", dest, err)
+			}
+			if !errors.Is(err, strconv.ErrRange) {
+				t.Errorf("expected RangeError to wrap strconv.ErrRange")
+			}
+		}
+	})
+
+	t.Run("negative values are rejected for unsigned types", func(t *testing.T) {
+		bad := New(testDriver{vals: map[string]string{"PORT": "-1"}})
+		var port struct {
+			Port uint16 `fig:"PORT"`
+		}
+		if err := bad.Unmarshal(&port); err == nil {
+			t.Errorf("expected error unmarshaling negative value into uint16")
+		}
+	})
+}
+
+type failingDriver struct{}
+
+func (d failingDriver) Name() string {
+	return "failing"
+}
+
+func (d failingDriver) Get(key string) (string, error) {
+	if key == "BROKEN" {
+		return "", errors.New("connection refused")
+	}
+	return "", ErrConfigNotFound
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	conf := New(failingDriver{}, testDriver{vals: map[string]string{
+		"PORT": "http",
+	}})
+
+	var ts struct {
+		Host    string        `fig:"HOST" required:"true"`
+		Port    int           `fig:"PORT"`
+		Timeout time.Duration `fig:"TIMEOUT" default:"soon"`
+		Broken  string        `fig:"BROKEN"`
+		DB      struct {
+			User string `fig:"USER" required:"true"`
+		} `prefix:"DB_"`
+		Optional string `fig:"OPTIONAL"`
+	}
+
+	err := conf.Unmarshal(&ts)
+	var unmarshalErrs UnmarshalErrors
+	if !errors.As(err, &unmarshalErrs) {
+		t.Fatalf("expected UnmarshalErrors, got %v", err)
+	}
+
+	t.Run("every failed field is reported", func(t *testing.T) {
+		exp := []FieldError{
+			{Field: "Host", Key: "HOST"},
+			{Field: "Port", Key: "PORT", Driver: "test"},
+			{Field: "Timeout", Key: "TIMEOUT", Driver: "default"},
+			{Field: "Broken", Key: "BROKEN", Driver: "failing"},
+			{Field: "DB.User", Key: "DB_USER"},
+		}
+		if len(unmarshalErrs) != len(exp) {
+			t.Fatalf("expected %d field errors, got %d: %s", len(exp), len(unmarshalErrs), err)
+		}
+		for i, fieldErr := range unmarshalErrs {
+			if fieldErr.Field != exp[i].Field || fieldErr.Key != exp[i].Key || fieldErr.Driver != exp[i].Driver {
+				t.Errorf("field error %d is %+v, expected %+v", i, *fieldErr, exp[i])
+			}
+			if fieldErr.Err == nil {
+				t.Errorf("field error %d has no cause", i)
+			}
+		}
+	})
+
+	t.Run("errors.Is finds causes", func(t *testing.T) {
+		if !errors.Is(err, ErrRequiredNotFound) {
+			t.Errorf("expected errors.Is to find ErrRequiredNotFound")
+		}
+		if !errors.Is(err, ErrConfigNotFound) {
+			t.Errorf("expected errors.Is to find ErrConfigNotFound")
+		}
+	})
+
+	t.Run("errors.As finds field errors", func(t *testing.T) {
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Field != "Host" {
+			t.Errorf("expected errors.As to find the first FieldError, got %v", fieldErr)
+		}
+	})
+
+	t.Run("error message lists every field", func(t *testing.T) {
+		for _, key := range []string{"HOST", "PORT", "TIMEOUT", "BROKEN", "DB_USER"} {
+			if !strings.Contains(err.Error(), key) {
+				t.Errorf("error message does not mention %s: %s", key, err)
+			}
+		}
+	})
+
+	t.Run("successful unmarshal returns nil error", func(t *testing.T) {
+		var ok struct {
+			Optional string `fig:"OPTIONAL"`
+		}
+		if err := conf.Unmarshal(&ok); err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,320 @@
+package fig
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Validator checks a field's value against a `validate` rule. value is the field's value, with
+// pointers dereferenced, and param is the text after the rule's '=', if any. A non-nil error
+// fails validation and becomes the Cause of a ValidationError.
+type Validator func(value interface{}, param string) error
+
+// validateFunc is the internal form of a Validator, working on the reflected value
+type validateFunc func(v reflect.Value, param string) error
+
+var (
+	validatorsMu sync.RWMutex
+	validators   = map[string]validateFunc{}
+)
+
+// built-in validators, consulted after user-registered validators
+var builtinValidators = map[string]validateFunc{
+	"min":      validateMin,
+	"max":      validateMax,
+	"len":      validateLen,
+	"oneof":    validateOneOf,
+	"regex":    validateRegex,
+	"nonzero":  validateNonzero,
+	"url":      validateURL,
+	"hostport": validateHostPort,
+}
+
+// RegisterValidator makes a validator available to `validate` tags under name. Registered
+// validators take precedence over fig's built-in rules, and registering a second validator with
+// the same name replaces the first.
+func RegisterValidator(name string, validate Validator) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators[name] = func(v reflect.Value, param string) error {
+		return validate(v.Interface(), param)
+	}
+}
+
+// unregisterValidator removes a validator added with RegisterValidator, so tests don't leak
+// registrations
+func unregisterValidator(name string) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	delete(validators, name)
+}
+
+// lookupValidator returns the registered or built-in validator for name, if any
+func lookupValidator(name string) (validateFunc, bool) {
+	validatorsMu.RLock()
+	validate, ok := validators[name]
+	validatorsMu.RUnlock()
+	if ok {
+		return validate, true
+	}
+
+	validate, ok = builtinValidators[name]
+	return validate, ok
+}
+
+// validationRule is a single rule from a `validate` tag, like min=1
+type validationRule struct {
+	name  string
+	param string
+}
+
+func (r validationRule) String() string {
+	if r.param == "" {
+		return r.name
+	}
+	return r.name + "=" + r.param
+}
+
+// parseValidationRules splits a `validate` tag into rules. Rules are comma separated, except that
+// regex takes the rest of the tag so its pattern may contain commas.
+func parseValidationRules(tag string) []validationRule {
+	var rules []validationRule
+	for tag = strings.TrimSpace(tag); tag != ""; tag = strings.TrimSpace(tag) {
+		part := tag
+		if strings.HasPrefix(tag, "regex=") {
+			tag = ""
+		} else if i := strings.IndexByte(tag, ','); i >= 0 {
+			part, tag = tag[:i], tag[i+1:]
+		} else {
+			tag = ""
+		}
+
+		name, param, _ := strings.Cut(part, "=")
+		if name = strings.TrimSpace(name); name != "" {
+			rules = append(rules, validationRule{name: name, param: param})
+		}
+	}
+	return rules
+}
+
+// checkValidationRules reports an unknown rule in a field's `validate` tag, so that typos are
+// caught whether or not the field has a value
+func checkValidationRules(f configField) error {
+	tag, ok := f.field.Tag.Lookup(validateTag)
+	if !ok {
+		return nil
+	}
+	for _, rule := range parseValidationRules(tag) {
+		if _, ok := lookupValidator(rule.name); !ok {
+			return &ValidationError{Key: f.key, Rule: rule.String(), Cause: errors.New("unknown validation rule")}
+		}
+	}
+	return nil
+}
+
+// validateField checks the value of a field against its `validate` tag. value is the configured
+// string the field was set from, for error reporting, and is masked for secret fields.
+func validateField(f configField, value string) error {
+	tag, ok := f.field.Tag.Lookup(validateTag)
+	if !ok {
+		return nil
+	}
+	if isSecret(f.field) {
+		value = secretMask
+	}
+
+	v := f.value
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	for _, rule := range parseValidationRules(tag) {
+		validate, ok := lookupValidator(rule.name)
+		if !ok {
+			return &ValidationError{Key: f.key, Value: value, Rule: rule.String(), Cause: errors.New("unknown validation rule")}
+		}
+		if err := validate(v, rule.param); err != nil {
+			return &ValidationError{Key: f.key, Value: value, Rule: rule.String(), Cause: err}
+		}
+	}
+	return nil
+}
+
+// hasLength reports whether min, max and len apply to the length of v rather than its value
+func hasLength(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return true
+	}
+	return false
+}
+
+// compare v with param, returning -1, 0 or 1 as v is less than, equal to or greater than it.
+// Durations are compared with params like "1s", and strings, slices and maps by length.
+func compare(v reflect.Value, param string) (int, error) {
+	if hasLength(v) {
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return 0, fmt.Errorf("invalid length %q", param)
+		}
+		return compareOrdered(v.Len(), n), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(param)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", param)
+			}
+			return compareOrdered(v.Int(), int64(d)), nil
+		}
+		n, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer %q", param)
+		}
+		return compareOrdered(v.Int(), n), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid unsigned integer %q", param)
+		}
+		return compareOrdered(v.Uint(), n), nil
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", param)
+		}
+		return compareOrdered(v.Float(), n), nil
+	}
+	return 0, fmt.Errorf("cannot compare values of type %s", v.Type())
+}
+
+func compareOrdered[T int | int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// describe what a bound applies to in error messages
+func boundSubject(v reflect.Value) string {
+	if hasLength(v) {
+		return "length"
+	}
+	return "value"
+}
+
+func validateMin(v reflect.Value, param string) error {
+	cmp, err := compare(v, param)
+	if err != nil {
+		return err
+	}
+	if cmp < 0 {
+		return fmt.Errorf("%s must be at least %s", boundSubject(v), param)
+	}
+	return nil
+}
+
+func validateMax(v reflect.Value, param string) error {
+	cmp, err := compare(v, param)
+	if err != nil {
+		return err
+	}
+	if cmp > 0 {
+		return fmt.Errorf("%s must be at most %s", boundSubject(v), param)
+	}
+	return nil
+}
+
+func validateLen(v reflect.Value, param string) error {
+	if !hasLength(v) {
+		return fmt.Errorf("values of type %s have no length", v.Type())
+	}
+	cmp, err := compare(v, param)
+	if err != nil {
+		return err
+	}
+	if cmp != 0 {
+		return fmt.Errorf("length must be %s", param)
+	}
+	return nil
+}
+
+// validateOneOf checks v against a | separated list of allowed values
+func validateOneOf(v reflect.Value, param string) error {
+	val := fmt.Sprint(v.Interface())
+	allowed := strings.Split(param, "|")
+	for _, a := range allowed {
+		if val == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+}
+
+func validateRegex(v reflect.Value, param string) error {
+	re, err := regexp.Compile(param)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+	if !re.MatchString(fmt.Sprint(v.Interface())) {
+		return fmt.Errorf("must match %s", param)
+	}
+	return nil
+}
+
+func validateNonzero(v reflect.Value, _ string) error {
+	if v.IsZero() || (hasLength(v) && v.Len() == 0) {
+		return errors.New("must not be zero or empty")
+	}
+	return nil
+}
+
+// validateURL checks that v is an absolute URL, either a string or a url.URL
+func validateURL(v reflect.Value, _ string) error {
+	var u *url.URL
+	switch val := v.Interface().(type) {
+	case url.URL:
+		u = &val
+	case string:
+		parsed, err := url.Parse(val)
+		if err != nil {
+			return fmt.Errorf("must be a URL: %w", err)
+		}
+		u = parsed
+	default:
+		return fmt.Errorf("values of type %s cannot be URLs", v.Type())
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return errors.New("must be an absolute URL with a scheme and host")
+	}
+	return nil
+}
+
+// validateHostPort checks that v is a host:port string, such as "db.local:5432" or ":8080"
+func validateHostPort(v reflect.Value, _ string) error {
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("values of type %s cannot be host:port addresses", v.Type())
+	}
+	_, port, err := net.SplitHostPort(v.String())
+	if err != nil || port == "" {
+		return errors.New("must be a host:port address")
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package fig
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseValidationRules(t *testing.T) {
+	cases := []struct {
+		tag      string
+		expected []validationRule
+	}{
+		{"", nil},
+		{"nonzero", []validationRule{{name: "nonzero"}}},
+		{"min=1, max=65535", []validationRule{{"min", "1"}, {"max", "65535"}}},
+		{"oneof=debug|info|warn", []validationRule{{"oneof", "debug|info|warn"}}},
+		{"len=3,regex=^[a-z]{1,3}$", []validationRule{{"len", "3"}, {"regex", "^[a-z]{1,3}$"}}},
+	}
+	for _, tc := range cases {
+		if rules := parseValidationRules(tc.tag); !reflect.DeepEqual(rules, tc.expected) {
+			t.Errorf("%q: expected %v, got %v", tc.tag, tc.expected, rules)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	type validated struct {
+		Port     int               `fig:"PORT" validate:"min=1,max=65535"`
+		Level    string            `fig:"LEVEL" validate:"oneof=debug|info|warn"`
+		Name     string            `fig:"NAME" validate:"regex=^[a-z]{2,4}$"`
+		Code     string            `fig:"CODE" validate:"len=3"`
+		Hosts    []string          `fig:"HOSTS" validate:"min=1,max=2"`
+		Timeout  time.Duration     `fig:"TIMEOUT" validate:"max=1m"`
+		Ratio    float64           `fig:"RATIO" validate:"min=0,max=1"`
+		Workers  uint              `fig:"WORKERS" validate:"nonzero"`
+		Endpoint string            `fig:"ENDPOINT" validate:"url"`
+		Homepage *url.URL          `fig:"HOMEPAGE" validate:"url"`
+		Addr     string            `fig:"ADDR" validate:"hostport"`
+		Labels   map[string]string `fig:"LABELS" validate:"nonzero"`
+		Optional int               `fig:"OPTIONAL" validate:"min=10"`
+		Default  int               `fig:"DEFAULT" default:"8" validate:"min=10"`
+	}
+
+	valid := map[string]string{
+		"PORT":     "8080",
+		"LEVEL":    "info",
+		"NAME":     "abc",
+		"CODE":     "xyz",
+		"HOSTS":    "a,b",
+		"TIMEOUT":  "30s",
+		"RATIO":    "0.5",
+		"WORKERS":  "4",
+		"ENDPOINT": "https://api.example.com/v1",
+		"HOMEPAGE": "https://example.com",
+		"ADDR":     ":5432",
+		"LABELS":   "team:core",
+		"DEFAULT":  "12",
+	}
+
+	t.Run("valid values", func(t *testing.T) {
+		var cfg validated
+		if err := New(MapDriver(valid)).Unmarshal(&cfg); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+
+	invalid := map[string]string{
+		"PORT":     "70000",
+		"LEVEL":    "trace",
+		"NAME":     "toolong",
+		"CODE":     "ab",
+		"HOSTS":    "a,b,c",
+		"TIMEOUT":  "2m",
+		"RATIO":    "1.5",
+		"WORKERS":  "0",
+		"ENDPOINT": "/v1",
+		"HOMEPAGE": "example.com",
+		"ADDR":     "localhost",
+		"LABELS":   "",
+	}
+	for key, val := range invalid {
+		key, val := key, val
+		t.Run(key, func(t *testing.T) {
+			vals := map[string]string{}
+			for k, v := range valid {
+				vals[k] = v
+			}
+			vals[key] = val
+
+			var cfg validated
+			err := New(MapDriver(vals)).Unmarshal(&cfg)
+			var fieldErrs UnmarshalErrors
+			var validationErr *ValidationError
+			if !errors.As(err, &fieldErrs) || len(fieldErrs) != 1 || fieldErrs[0].Key != key || fieldErrs[0].Driver != "map" {
+				t.Fatalf("expected a single error for %s, got %v", key, err)
+			}
+			if !errors.As(err, &validationErr) || validationErr.Key != key || validationErr.Value != val {
+				t.Errorf("expected ValidationError for %s, got %v", key, err)
+			}
+		})
+	}
+
+	t.Run("unset fields are not validated", func(t *testing.T) {
+		vals := map[string]string{}
+		for k, v := range valid {
+			vals[k] = v
+		}
+		delete(vals, "DEFAULT")
+
+		var cfg validated
+		err := New(MapDriver(vals)).Unmarshal(&cfg)
+		var fieldErrs UnmarshalErrors
+		if !errors.As(err, &fieldErrs) || len(fieldErrs) != 1 || fieldErrs[0].Key != "DEFAULT" || fieldErrs[0].Driver != defaultDriverName {
+			t.Errorf("expected only the default value to fail, got %v", err)
+		}
+	})
+}
+
+func TestValidateErrors(t *testing.T) {
+	type secretConfig struct {
+		Key string `fig:"API_KEY" secret:"true" validate:"len=32"`
+	}
+	type unknownRule struct {
+		Level string `fig:"LEVEL" validate:"loud"`
+	}
+	type unknownUnsetRule struct {
+		Color string `fig:"COLOR" validate:"nonzro"`
+	}
+	type badParam struct {
+		Port int `fig:"PORT" validate:"min=one"`
+	}
+
+	conf := New(MapDriver{"API_KEY": "hunter2", "LEVEL": "info", "PORT": "80"})
+
+	var secret secretConfig
+	err := conf.Unmarshal(&secret)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Value != secretMask || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("expected masked ValidationError, got %v", err)
+	}
+
+	var unknown unknownRule
+	if err := conf.Unmarshal(&unknown); !errors.As(err, &validationErr) || validationErr.Rule != "loud" {
+		t.Errorf("expected ValidationError for unknown rule, got %v", err)
+	}
+
+	var unknownUnset unknownUnsetRule
+	if err := conf.Unmarshal(&unknownUnset); !errors.As(err, &validationErr) || validationErr.Rule != "nonzro" {
+		t.Errorf("expected ValidationError for unknown rule on unset field, got %v", err)
+	}
+
+	var bad badParam
+	if err := conf.Unmarshal(&bad); !errors.As(err, &validationErr) || validationErr.Rule != "min=one" {
+		t.Errorf("expected ValidationError for invalid parameter, got %v", err)
+	}
+}
+
+func TestRegisterValidator(t *testing.T) {
+	errOdd := errors.New("must be even")
+	RegisterValidator("even", func(value interface{}, _ string) error {
+		if n, ok := value.(int); ok && n%2 != 0 {
+			return errOdd
+		}
+		return nil
+	})
+	RegisterValidator("prefix", func(value interface{}, param string) error {
+		if !strings.HasPrefix(fmt.Sprint(value), param) {
+			return fmt.Errorf("must start with %s", param)
+		}
+		return nil
+	})
+	t.Cleanup(func() {
+		unregisterValidator("even")
+		unregisterValidator("prefix")
+	})
+
+	type custom struct {
+		Replicas int    `fig:"REPLICAS" validate:"even,min=2"`
+		Bucket   string `fig:"BUCKET" validate:"prefix=prod-"`
+	}
+
+	var cfg custom
+	err := New(MapDriver{"REPLICAS": "3", "BUCKET": "prod-assets"}).Unmarshal(&cfg)
+	if !errors.Is(err, errOdd) {
+		t.Errorf("expected registered validator error, got %v", err)
+	}
+	if cfg.Replicas != 3 {
+		t.Errorf("expected invalid value to be assigned, got %d", cfg.Replicas)
+	}
+
+	err = New(MapDriver{"REPLICAS": "4", "BUCKET": "dev-assets"}).Unmarshal(&cfg)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Rule != "prefix=prod-" {
+		t.Errorf("expected ValidationError for prefix, got %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package fig
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PollInterval is how often drivers check their files for changes while being watched
+var PollInterval = 2 * time.Second
+
+// Watcher may be implemented by drivers whose values can change while a program is running, such
+// as drivers that read files. Config.Watch reports changes from every driver implementing it.
+type Watcher interface {
+	// Watch should block until ctx is done, calling changed after the driver's values change,
+	// and then return ctx.Err()
+	Watch(ctx context.Context, changed func()) error
+}
+
+// Change describes a change to a Config's values
+type Change struct {
+	// Driver is the Name of the driver whose values changed
+	Driver string
+	// Key is the key that changed, for changes reported by OnChange
+	Key string
+	// Old and New are the key's values before and after the change, for changes reported by
+	// OnChange. A missing key has an empty value.
+	Old, New string
+}
+
+// watchableDriver is implemented by Watchers that may have nothing to watch, such as wrappers
+// around drivers that aren't Watchers and environment drivers without .env files
+type watchableDriver interface {
+	watchable() bool
+}
+
+// isWatchable reports whether driver implements Watcher and has something to watch
+func isWatchable(driver Driver) bool {
+	if _, ok := driver.(Watcher); !ok {
+		return false
+	}
+	if w, ok := driver.(watchableDriver); ok {
+		return w.watchable()
+	}
+	return true
+}
+
+// Watch blocks until ctx is done, calling fn after the values of any driver implementing
+// Watcher change. fn is never called concurrently. If none of c's drivers can be watched,
+// including wrappers of unwatchable drivers and environment drivers without .env files,
+// ErrNotWatchable is returned immediately.
+func (c Config) Watch(ctx context.Context, fn func(Change)) error {
+	var watchers []Driver
+	for _, driver := range c.drivers {
+		if isWatchable(driver) {
+			watchers = append(watchers, driver)
+		}
+	}
+	if len(watchers) == 0 {
+		return ErrNotWatchable
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	errs := make(chan error, len(watchers))
+	for _, driver := range watchers {
+		driver := driver
+		go func() {
+			errs <- driver.(Watcher).Watch(ctx, func() {
+				mu.Lock()
+				defer mu.Unlock()
+				fn(Change{Driver: driver.Name()})
+			})
+		}()
+	}
+
+	// a watcher stopping early stops the rest
+	err := <-errs
+	cancel()
+	for i := 1; i < len(watchers); i++ {
+		<-errs
+	}
+	return err
+}
+
+// OnChange blocks until ctx is done, calling fn when the value of key changes. It is built on
+// Watch and returns the same errors.
+func (c Config) OnChange(ctx context.Context, key string, fn func(Change)) error {
+	current, _ := c.get(key)
+	return c.Watch(ctx, func(change Change) {
+		val, _ := c.get(key)
+		if val == current {
+			return
+		}
+		change.Key, change.Old, change.New = key, current, val
+		current = val
+		fn(change)
+	})
+}
+
+// ReloadingConfig holds a struct unmarshaled from a Config, replacing it with a freshly
+// unmarshaled value whenever the Config's values change. Load is safe to call concurrently with
+// reloads.
+type ReloadingConfig[T any] struct {
+	conf    Config
+	current atomic.Pointer[T]
+}
+
+// NewReloadingConfig unmarshals conf into a new T. Call Watch to keep it up to date.
+func NewReloadingConfig[T any](conf Config) (*ReloadingConfig[T], error) {
+	r := &ReloadingConfig[T]{conf: conf}
+	val := new(T)
+	if err := conf.Unmarshal(val); err != nil {
+		return nil, err
+	}
+	r.current.Store(val)
+	return r, nil
+}
+
+// Load returns the current value. It must not be modified, since it may be shared with other
+// goroutines.
+func (r *ReloadingConfig[T]) Load() *T {
+	return r.current.Load()
+}
+
+// Watch blocks until ctx is done, unmarshaling into a new T after each change. If unmarshaling
+// fails the previous value is kept. onReload, if not nil, is called after each attempt with the
+// value now in use and any error.
+func (r *ReloadingConfig[T]) Watch(ctx context.Context, onReload func(*T, error)) error {
+	return r.conf.Watch(ctx, func(Change) {
+		val := new(T)
+		err := r.conf.Unmarshal(val)
+		if err == nil {
+			r.current.Store(val)
+		}
+		if onReload != nil {
+			onReload(r.current.Load(), err)
+		}
+	})
+}
+
+// watchDriver watches driver if it implements Watcher, for drivers that wrap another
+func watchDriver(ctx context.Context, driver Driver, changed func()) error {
+	if watcher, ok := driver.(Watcher); ok {
+		return watcher.Watch(ctx, changed)
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// poll calls check every PollInterval until ctx is done
+func poll(ctx context.Context, check func()) error {
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// fileStamp identifies a version of a file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+// stat a file, following symlinks so that swapped mounts are noticed
+func stampFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size(), exists: true}
+}
+
+func (s fileStamp) equal(other fileStamp) bool {
+	return s.exists == other.exists && s.size == other.size && s.modTime.Equal(other.modTime)
+}
+
+// watchedFiles holds a value loaded from a set of files, reloading it when any of them change.
+// Drivers share a pointer to it, so copies of a driver see reloaded values.
+type watchedFiles[T any] struct {
+	paths []string
+	load  func() (T, error)
+
+	mu      sync.RWMutex
+	value   T
+	stamps  []fileStamp
+	version uint64
+}
+
+// loadWatchedFiles loads the initial value from paths
+func loadWatchedFiles[T any](paths []string, load func() (T, error)) (*watchedFiles[T], error) {
+	stamps := stampFiles(paths)
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+	return &watchedFiles[T]{paths: paths, load: load, value: value, stamps: stamps}, nil
+}
+
+func stampFiles(paths []string) []fileStamp {
+	stamps := make([]fileStamp, len(paths))
+	for i, path := range paths {
+		stamps[i] = stampFile(path)
+	}
+	return stamps
+}
+
+// get returns the current value, or the zero value for a driver created without files
+func (w *watchedFiles[T]) get() T {
+	if w == nil {
+		var zero T
+		return zero
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.value
+}
+
+// reload loads the files again if they have changed since they were last loaded. If loading
+// fails, for example because a file is only partly written, the previous value is kept and the
+// next reload tries again.
+func (w *watchedFiles[T]) reload() {
+	stamps := stampFiles(w.paths)
+
+	w.mu.RLock()
+	unchanged := true
+	for i := range stamps {
+		unchanged = unchanged && stamps[i].equal(w.stamps[i])
+	}
+	w.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	value, err := w.load()
+	if err != nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.value, w.stamps = value, stamps
+	w.version++
+}
+
+func (w *watchedFiles[T]) currentVersion() uint64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.version
+}
+
+// watch polls the files, calling changed after each reload. Each watch tracks the version it has
+// seen, so several Configs can watch the same driver.
+func (w *watchedFiles[T]) watch(ctx context.Context, changed func()) error {
+	if w == nil {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	seen := w.currentVersion()
+	return poll(ctx, func() {
+		w.reload()
+		if version := w.currentVersion(); version != seen {
+			seen = version
+			changed()
+		}
+	})
+}
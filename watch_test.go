@@ -0,0 +1,219 @@
+package fig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+// shorten PollInterval for the rest of the test
+func fastPolling(t *testing.T) {
+	prev := PollInterval
+	PollInterval = 5 * time.Millisecond
+	t.Cleanup(func() {
+		PollInterval = prev
+	})
+}
+
+// rewrite a test file, moving its modification time so the change is noticed on coarse clocks
+func rewriteTestFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// wait for a value on ch, failing the test after a timeout
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case val := <-ch:
+		return val
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for change")
+		panic("unreachable")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	fastPolling(t)
+
+	path := writeTestFile(t, "config.json", `{"rate": 10}`)
+	driver, err := NewJSONDriver(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := New(MapDriver{}, driver)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan Change, 10)
+	done := make(chan error)
+	go func() {
+		done <- conf.Watch(ctx, func(change Change) {
+			changes <- change
+		})
+	}()
+
+	rewriteTestFile(t, path, `{"rate": 250}`)
+	if change := receive(t, changes); change.Driver != "json" {
+		t.Errorf("expected change from json driver, got %+v", change)
+	}
+	if rate := conf.MustGetInt("rate"); rate != 250 {
+		t.Errorf("expected reloaded rate 250, got %d", rate)
+	}
+
+	t.Run("malformed files keep previous values", func(t *testing.T) {
+		rewriteTestFile(t, path, `{"rate": `)
+		time.Sleep(50 * time.Millisecond)
+		if rate := conf.MustGetInt("rate"); rate != 250 {
+			t.Errorf("expected previous rate 250, got %d", rate)
+		}
+	})
+
+	cancel()
+	if err := receive(t, done); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	t.Run("configs without watchable drivers", func(t *testing.T) {
+		envDriver, err := NewEnvironmentDriver()
+		if err != nil {
+			t.Fatalf("unexpected error creating env driver: %s", err)
+		}
+
+		for name, conf := range map[string]Config{
+			"plain driver":          New(MapDriver{}),
+			"Sub":                   Sub(New(MapDriver{}), "DB_"),
+			"WithPrefix":            New(WithPrefix(MapDriver{}, "APP_")),
+			"WithFileSuffix":        New(WithFileSuffix(MapDriver{})),
+			"env driver, no files":  New(envDriver),
+			"wrapped env, no files": New(WithPrefix(envDriver, "APP_")),
+		} {
+			if err := conf.Watch(context.Background(), func(Change) {}); !errors.Is(err, ErrNotWatchable) {
+				t.Errorf("%s: expected ErrNotWatchable from Watch, got %v", name, err)
+			}
+			if err := conf.OnChange(context.Background(), "KEY", func(Change) {}); !errors.Is(err, ErrNotWatchable) {
+				t.Errorf("%s: expected ErrNotWatchable from OnChange, got %v", name, err)
+			}
+		}
+
+		if !isWatchable(WithPrefix(WithFileSuffix(driver), "APP_")) {
+			t.Errorf("expected wrappers of a file driver to be watchable")
+		}
+	})
+}
+
+func TestOnChange(t *testing.T) {
+	fastPolling(t)
+
+	path := writeTestFile(t, "test.env", "LOG_LEVEL=info\nRATE=10\n")
+	driver, err := NewEnvironmentDriver(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan Change, 10)
+	go New(driver).OnChange(ctx, "RATE", func(change Change) {
+		changes <- change
+	})
+
+	rewriteTestFile(t, path, "LOG_LEVEL=debug\nRATE=10\n")
+	rewriteTestFile(t, path, "LOG_LEVEL=debug\nRATE=500\n")
+	change := receive(t, changes)
+	if change.Key != "RATE" || change.Old != "10" || change.New != "500" || change.Driver != "env" {
+		t.Errorf("unexpected change %+v", change)
+	}
+}
+
+func TestReloadingConfig(t *testing.T) {
+	fastPolling(t)
+
+	type workerConfig struct {
+		RateLimit int    `fig:"RATE_LIMIT" required:"true"`
+		Queue     string `fig:"QUEUE" default:"jobs"`
+	}
+
+	path := writeTestFile(t, "worker.properties", "RATE_LIMIT=10\n")
+	driver, err := NewPropertiesDriver(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloading, err := NewReloadingConfig[workerConfig](New(driver))
+	if err != nil {
+		t.Fatal(err)
+	}
+	initial := reloading.Load()
+	if initial.RateLimit != 10 || initial.Queue != "jobs" {
+		t.Errorf("unexpected initial config %+v", initial)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	type reload struct {
+		cfg *workerConfig
+		err error
+	}
+	reloads := make(chan reload, 10)
+	go reloading.Watch(ctx, func(cfg *workerConfig, err error) {
+		reloads <- reload{cfg, err}
+	})
+
+	rewriteTestFile(t, path, "RATE_LIMIT=100\nQUEUE=priority\n")
+	r := receive(t, reloads)
+	if r.err != nil || r.cfg.RateLimit != 100 || r.cfg.Queue != "priority" {
+		t.Errorf("unexpected reload %+v (%v)", r.cfg, r.err)
+	}
+	if reloading.Load() != r.cfg {
+		t.Errorf("expected Load to return the reloaded config")
+	}
+	if initial.RateLimit != 10 {
+		t.Errorf("expected the previous config to be left unmodified")
+	}
+
+	t.Run("invalid values keep the previous config", func(t *testing.T) {
+		rewriteTestFile(t, path, "RATE_LIMIT=lots\n")
+		r := receive(t, reloads)
+		if r.err == nil || r.cfg.RateLimit != 100 || reloading.Load().RateLimit != 100 {
+			t.Errorf("expected error and previous config, got %+v (%v)", r.cfg, r.err)
+		}
+	})
+
+	t.Run("new config fails on invalid values", func(t *testing.T) {
+		if _, err := NewReloadingConfig[workerConfig](New(MapDriver{})); err == nil {
+			t.Errorf("expected error for missing required field")
+		}
+	})
+}
+
+func TestDirectoryWatch(t *testing.T) {
+	fastPolling(t)
+
+	dir := t.TempDir()
+	driver, err := NewDirectoryDriver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan Change, 10)
+	go New(driver).Watch(ctx, func(change Change) {
+		changes <- change
+	})
+
+	// let Watch record the empty directory first
+	time.Sleep(50 * time.Millisecond)
+	rewriteTestFile(t, dir+"/DB_PASSWORD", "hunter2")
+	if change := receive(t, changes); change.Driver != "dir" {
+		t.Errorf("unexpected change %+v", change)
+	}
+}
@@ -0,0 +1,154 @@
+package fig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLDriver supports reading from a YAML file. Keys are paths into the document, such as
+// "db.host" or "servers[0].port". Anchors, aliases and merge keys are resolved.
+type YAMLDriver struct {
+	treeFileDriver
+}
+
+// YAMLOption configures a YAMLDriver
+type YAMLOption func(*yamlOptions)
+
+type yamlOptions struct {
+	index      int
+	matchKey   string
+	matchValue string
+}
+
+// YAMLDocumentIndex selects the document at index i of a multi-document file, counting from 0
+func YAMLDocumentIndex(i int) YAMLOption {
+	return func(o *yamlOptions) {
+		o.index = i
+		o.matchKey = ""
+	}
+}
+
+// YAMLDocument selects the first document of a multi-document file whose value at key equals
+// value, e.g. YAMLDocument("metadata.name", "app-config")
+func YAMLDocument(key, value string) YAMLOption {
+	return func(o *yamlOptions) {
+		o.matchKey = key
+		o.matchValue = value
+	}
+}
+
+// NewYAMLDriver reads and parses the YAML file at path. By default the first document of a
+// multi-document file is used. A malformed file, or one without the selected document, is an
+// error.
+func NewYAMLDriver(path string, opts ...YAMLOption) (YAMLDriver, error) {
+	options := yamlOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	driver, err := loadTreeFile(path, "YAML", func(data []byte) (interface{}, error) {
+		docs, err := parseYAML(data)
+		if err != nil {
+			return nil, err
+		}
+		return selectYAMLDocument(docs, options)
+	})
+	if err != nil {
+		return YAMLDriver{}, err
+	}
+	return YAMLDriver{treeFileDriver: driver}, nil
+}
+
+// parse every document in a YAML stream
+func parseYAML(data []byte) ([]interface{}, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	var docs []interface{}
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		keepYAMLDates(&node)
+		var doc interface{}
+		if err := node.Decode(&doc); err != nil {
+			return nil, err
+		}
+		docs = append(docs, normalizeYAML(doc))
+	}
+}
+
+// keepYAMLDates retags date-only timestamps such as 2001-12-14 as strings, so they are returned
+// as written rather than as midnight UTC, matching TOML local dates
+func keepYAMLDates(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!timestamp" && !strings.ContainsAny(node.Value, "Tt ") {
+		node.Tag = "!!str"
+	}
+	for _, child := range node.Content {
+		keepYAMLDates(child)
+	}
+}
+
+// normalizeYAML converts a decoded YAML document to the types lookupPath and renderValue handle.
+// Mappings become map[string]interface{}, with non-string keys such as 1 or true keyed by their
+// text, and timestamps with a time of day become RFC 3339 strings.
+func normalizeYAML(node interface{}) interface{} {
+	switch n := node.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(n))
+		for k, v := range n {
+			m[fmt.Sprint(k)] = normalizeYAML(v)
+		}
+		return m
+	case map[string]interface{}:
+		for k, v := range n {
+			n[k] = normalizeYAML(v)
+		}
+	case []interface{}:
+		for i, v := range n {
+			n[i] = normalizeYAML(v)
+		}
+	case time.Time:
+		return n.Format(time.RFC3339Nano)
+	}
+	return node
+}
+
+// pick the document described by options. An empty file has no documents and yields nil.
+func selectYAMLDocument(docs []interface{}, options yamlOptions) (interface{}, error) {
+	if options.matchKey != "" {
+		for _, doc := range docs {
+			val, ok := lookupPath(doc, options.matchKey)
+			if !ok {
+				continue
+			}
+			if rendered, err := renderValue(val); err == nil && rendered == options.matchValue {
+				return doc, nil
+			}
+		}
+		return nil, fmt.Errorf("no document with %s %s", options.matchKey, options.matchValue)
+	}
+
+	if len(docs) == 0 && options.index == 0 {
+		return nil, nil
+	}
+	if options.index < 0 || options.index >= len(docs) {
+		return nil, fmt.Errorf("document index %d out of range: file has %d documents", options.index, len(docs))
+	}
+	return docs[options.index], nil
+}
+
+func (d YAMLDriver) Name() string {
+	return "yaml"
+}
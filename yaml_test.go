@@ -0,0 +1,164 @@
+package fig
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const testYAML = `
+defaults: &defaults
+  host: db.internal
+  port: 5432
+  timeout: 5s
+db:
+  <<: *defaults
+  port: 6432
+replica: *defaults
+servers:
+  - name: a
+    port: 8080
+  - name: b
+    port: 8081
+origins: [a.com, b.com]
+started: 2023-04-05T06:07:08Z
+when: 2001-12-14
+empty: ~
+codes:
+  404: not found
+  true: yes
+`
+
+const testMultiYAML = `
+metadata:
+  name: base
+value: first
+---
+metadata:
+  name: app-config
+value: second
+---
+value: third
+`
+
+func TestYAMLDriver(t *testing.T) {
+	driver, err := NewYAMLDriver(writeTestFile(t, "config.yaml", testYAML))
+	if err != nil {
+		t.Fatalf("unexpected error creating YAML driver: %s", err)
+	}
+
+	t.Run("paths resolve through anchors and merge keys", func(t *testing.T) {
+		cases := map[string]string{
+			"db.host":         "db.internal",
+			"db.port":         "6432",
+			"replica.port":    "5432",
+			"replica.timeout": "5s",
+			"servers[1].name": "b",
+			"origins":         "a.com,b.com",
+			"started":         "2023-04-05T06:07:08Z",
+			"when":            "2001-12-14",
+			"codes.404":       "not found",
+			"codes.true":      "yes",
+		}
+		for key, exp := range cases {
+			val, err := driver.Get(key)
+			if err != nil {
+				t.Errorf("unexpected error for key %s: %s", key, err)
+			}
+			if val != exp {
+				t.Errorf("unexpected value %s for key %s, expected %s", val, key, exp)
+			}
+		}
+	})
+
+	t.Run("missing paths return ErrConfigNotFound", func(t *testing.T) {
+		for _, key := range []string{"db.user", "servers[5]", "empty"} {
+			if _, err := driver.Get(key); !errors.Is(err, ErrConfigNotFound) {
+				t.Errorf("expected ErrConfigNotFound for key %s, got %v", key, err)
+			}
+		}
+	})
+
+	t.Run("Unmarshal reads typed values", func(t *testing.T) {
+		var ts struct {
+			Timeout time.Duration `fig:"db.timeout"`
+			Started time.Time     `fig:"started"`
+			When    time.Time     `fig:"when" layout:"2006-01-02"`
+		}
+		if err := New(driver).Unmarshal(&ts); err != nil {
+			t.Fatalf("unexpected error from Unmarshal: %s", err)
+		}
+		if ts.Timeout != 5*time.Second {
+			t.Errorf("unexpected duration %s", ts.Timeout)
+		}
+		if !ts.Started.Equal(time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)) {
+			t.Errorf("unexpected time %s", ts.Started)
+		}
+		if !ts.When.Equal(time.Date(2001, 12, 14, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("unexpected date %s", ts.When)
+		}
+	})
+
+	t.Run("environment takes precedence over YAML", func(t *testing.T) {
+		t.Setenv("db.host", "override")
+		envDriver, err := NewEnvironmentDriver()
+		if err != nil {
+			t.Fatal(err)
+		}
+		val, src, err := New(envDriver, driver).Lookup("db.host")
+		if err != nil || val != "override" || src.Driver != "env" {
+			t.Errorf("expected environment to take precedence, got %s from %s (%v)", val, src, err)
+		}
+	})
+
+	t.Run("malformed files fail construction", func(t *testing.T) {
+		if _, err := NewYAMLDriver(writeTestFile(t, "bad.yaml", "db: [unclosed")); err == nil {
+			t.Errorf("expected error for malformed YAML")
+		}
+	})
+}
+
+func TestYAMLDocuments(t *testing.T) {
+	path := writeTestFile(t, "multi.yaml", testMultiYAML)
+
+	cases := []struct {
+		name string
+		opts []YAMLOption
+		exp  string
+	}{
+		{"first document by default", nil, "first"},
+		{"document by index", []YAMLOption{YAMLDocumentIndex(2)}, "third"},
+		{"document by name", []YAMLOption{YAMLDocument("metadata.name", "app-config")}, "second"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			driver, err := NewYAMLDriver(path, c.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error creating YAML driver: %s", err)
+			}
+			val, err := driver.Get("value")
+			if err != nil || val != c.exp {
+				t.Errorf("expected %s, got %s (%v)", c.exp, val, err)
+			}
+		})
+	}
+
+	t.Run("missing documents fail construction", func(t *testing.T) {
+		if _, err := NewYAMLDriver(path, YAMLDocumentIndex(3)); err == nil {
+			t.Errorf("expected error for out of range document index")
+		}
+		if _, err := NewYAMLDriver(path, YAMLDocument("metadata.name", "nope")); err == nil {
+			t.Errorf("expected error for unknown document name")
+		}
+	})
+
+	t.Run("empty files have no keys", func(t *testing.T) {
+		driver, err := NewYAMLDriver(writeTestFile(t, "empty.yaml", ""))
+		if err != nil {
+			t.Fatalf("unexpected error for empty file: %s", err)
+		}
+		if _, err := driver.Get("value"); !errors.Is(err, ErrConfigNotFound) {
+			t.Errorf("expected ErrConfigNotFound from empty file, got %v", err)
+		}
+	})
+}